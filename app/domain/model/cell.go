@@ -1,8 +1,27 @@
 package model
 
+// Cell fields are kept small so large boards stay cheap: a cell takes 4
+// bytes.
 type Cell struct {
-	Mine        bool `json:"mine"`
-	Revealed    bool `json:"revealed"`
-	Flagged     bool `json:"flagged"`
-	MinesAround int  `json:"mines_around"`
+	Mine        bool  `json:"mine"`
+	Revealed    bool  `json:"revealed"`
+	Mark        Mark  `json:"mark"`
+	MinesAround uint8 `json:"mines_around"`
+}
+
+// NewGrid returns a rows x cols grid of hidden cells, backed by a single
+// allocation.
+func NewGrid(rows, cols int) [][]Cell {
+	cells := make([]Cell, rows*cols)
+	grid := make([][]Cell, rows)
+	for i := range grid {
+		grid[i] = cells[i*cols : (i+1)*cols : (i+1)*cols]
+	}
+	return grid
+}
+
+// Flagged tells if the player marked the cell as a mine. Flagged cells
+// can't be revealed.
+func (c Cell) Flagged() bool {
+	return c.Mark == Flag
 }
@@ -0,0 +1,419 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type EventKind string
+
+const (
+	CreatedEvent     EventKind = "created"
+	MinesPlacedEvent EventKind = "mines_placed"
+	RevealedEvent    EventKind = "revealed"
+	FlaggedEvent     EventKind = "flagged"
+	ChordedEvent     EventKind = "chorded"
+	PausedEvent      EventKind = "paused"
+	ResumedEvent     EventKind = "resumed"
+	UndoneEvent      EventKind = "undone"
+	HintedEvent      EventKind = "hinted"
+	FinishedEvent    EventKind = "finished"
+)
+
+// Event is a change on a game. Games are only changed by applying events,
+// so a game is the projection of the events stored for it. Each kind only
+// fills the fields it needs.
+type Event struct {
+	GameID             string        `json:"game_id"`
+	Seq                int           `json:"seq"`
+	Kind               EventKind     `json:"kind"`
+	Time               time.Time     `json:"time"`
+	Settings           *GameSettings `json:"settings,omitempty"`
+	Mines              []Coordinate  `json:"mines,omitempty"`
+	GenerationAttempts int           `json:"generation_attempts,omitempty"`
+	GenerationTime     time.Duration `json:"generation_time,omitempty"`
+	Cell               *Coordinate   `json:"cell,omitempty"`
+	Mark               Mark          `json:"mark,omitempty"`
+	Moves              int           `json:"moves,omitempty"`
+	Reason             FinishReason  `json:"reason,omitempty"`
+	EndedBy            Actor         `json:"ended_by,omitempty"`
+}
+
+// GameSettings are the game fields chosen on creation, that no move
+// changes afterwards.
+type GameSettings struct {
+	TimeLimit      int          `json:"time_limit,omitempty"`
+	Rows           int          `json:"rows"`
+	Cols           int          `json:"cols"`
+	Mines          int          `json:"mines"`
+	Preset         string       `json:"preset,omitempty"`
+	Topology       TopologyKind `json:"topology,omitempty"`
+	Wrap           bool         `json:"wrap"`
+	MarkCycle      []Mark       `json:"mark_cycle,omitempty"`
+	Seed           int64        `json:"seed,omitempty"`
+	FirstClickSafe bool         `json:"first_click_safe"`
+	SafeNeighbours bool         `json:"safe_neighbours"`
+	NoGuess        bool         `json:"no_guess"`
+	Practice       bool         `json:"practice"`
+	RestartedFrom  string       `json:"restarted_from,omitempty"`
+}
+
+// UnmarshalJSON also reads the numeric game IDs stored before IDs were
+// strings.
+func (e *Event) UnmarshalJSON(data []byte) error {
+	type event Event
+	stored := struct {
+		*event
+		GameID json.RawMessage `json:"game_id"`
+	}{event: (*event)(e)}
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return err
+	}
+
+	ID, err := unmarshalID(stored.GameID)
+	if err != nil {
+		return err
+	}
+	e.GameID = ID
+	return nil
+}
+
+// UnmarshalJSON also reads the numeric source game IDs stored before IDs
+// were strings.
+func (s *GameSettings) UnmarshalJSON(data []byte) error {
+	type settings GameSettings
+	stored := struct {
+		*settings
+		RestartedFrom json.RawMessage `json:"restarted_from"`
+	}{settings: (*settings)(s)}
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return err
+	}
+
+	ID, err := unmarshalID(stored.RestartedFrom)
+	if err != nil {
+		return err
+	}
+	s.RestartedFrom = ID
+	return nil
+}
+
+// unmarshalID reads a game ID either as a string or as a number.
+func unmarshalID(data json.RawMessage) (string, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return "", nil
+	}
+	if data[0] != '"' {
+		var number json.Number
+		err := json.Unmarshal(data, &number)
+		return number.String(), err
+	}
+
+	var ID string
+	err := json.Unmarshal(data, &ID)
+	return ID, err
+}
+
+// NewCreatedEvent records the creation of a game, along with its mine
+// layout when the mines are already placed.
+func NewCreatedEvent(game *Game, now time.Time) Event {
+	event := Event{
+		Kind: CreatedEvent,
+		Time: now,
+		Settings: &GameSettings{
+			TimeLimit:      game.TimeLimit,
+			Rows:           game.Rows,
+			Cols:           game.Cols,
+			Mines:          game.Mines,
+			Preset:         game.Preset,
+			Topology:       game.Topology,
+			Wrap:           game.Wrap,
+			MarkCycle:      game.MarkCycle,
+			Seed:           game.Seed,
+			FirstClickSafe: game.FirstClickSafe,
+			SafeNeighbours: game.SafeNeighbours,
+			NoGuess:        game.NoGuess,
+			Practice:       game.Practice,
+			RestartedFrom:  game.RestartedFrom,
+		},
+	}
+	if game.MinesPlaced {
+		event.Mines = game.MineCoordinates()
+		event.GenerationAttempts = game.GenerationAttempts
+		event.GenerationTime = game.GenerationTime
+	}
+	return event
+}
+
+// NewMinesPlacedEvent records the mine layout placed on the game.
+func NewMinesPlacedEvent(game *Game, now time.Time) Event {
+	return Event{
+		Kind:               MinesPlacedEvent,
+		Time:               now,
+		Mines:              game.MineCoordinates(),
+		GenerationAttempts: game.GenerationAttempts,
+		GenerationTime:     game.GenerationTime,
+	}
+}
+
+// NewCellEvent records a move on a game cell.
+func NewCellEvent(kind EventKind, row, col int, now time.Time) Event {
+	return Event{
+		Kind: kind,
+		Time: now,
+		Cell: &Coordinate{Row: row, Col: col},
+	}
+}
+
+// NewFinishedEvent records the end of a game.
+func NewFinishedEvent(reason FinishReason, by Actor, now time.Time) Event {
+	return Event{
+		Kind:    FinishedEvent,
+		Time:    now,
+		Reason:  reason,
+		EndedBy: by,
+	}
+}
+
+// Project builds a game again from its events.
+func Project(events []Event) *Game {
+	game := &Game{}
+	for _, event := range events {
+		game.Apply(event)
+	}
+	return game
+}
+
+// Apply changes the game by the given event. The event time is used in
+// place of the current one, so projecting the same events always gives
+// the same game. The game version counts the events applied to it.
+func (g *Game) Apply(event Event) {
+
+	switch event.Kind {
+	case CreatedEvent:
+		g.create(event)
+	case MinesPlacedEvent:
+		g.layMines(event.Mines)
+		g.GenerationAttempts = event.GenerationAttempts
+		g.GenerationTime = event.GenerationTime
+	case RevealedEvent:
+		g.reveal(event.Cell.Row, event.Cell.Col)
+		g.record(RevealMove, event)
+	case FlaggedEvent:
+		g.Grid[event.Cell.Row][event.Cell.Col].Mark = event.Mark
+		g.record(FlagMove, event)
+	case ChordedEvent:
+		g.chord(event.Cell.Row, event.Cell.Col)
+		g.record(ChordMove, event)
+	case PausedEvent:
+		g.ActiveTime = g.Elapsed(event.Time)
+		g.LastMoveTime = event.Time
+		g.Status = Paused
+	case ResumedEvent:
+		g.ResumeTime = event.Time
+		g.LastMoveTime = event.Time
+		g.Status = Running
+	case UndoneEvent:
+		g.undo(event)
+	case HintedEvent:
+		g.HintsUsed++
+	case FinishedEvent:
+		g.end(event)
+	}
+	g.Version++
+}
+
+// MineCoordinates returns where the game mines are.
+func (g *Game) MineCoordinates() []Coordinate {
+	mines := make([]Coordinate, 0, g.Mines)
+	for x := range g.Grid {
+		for y := range g.Grid[x] {
+			if g.Grid[x][y].Mine {
+				mines = append(mines, Coordinate{Row: x, Col: y})
+			}
+		}
+	}
+	return mines
+}
+
+// CountMinesAround sets how many mines each cell has around.
+func (g *Game) CountMinesAround() {
+	board := g.Board()
+	var neighbours []Coordinate
+	for x := range g.Grid {
+		for y := range g.Grid[x] {
+			if !g.Grid[x][y].Mine {
+				continue
+			}
+			neighbours = board.AppendNeighbours(neighbours[:0], Coordinate{Row: x, Col: y})
+			for _, n := range neighbours {
+				g.Grid[n.Row][n.Col].MinesAround++
+			}
+		}
+	}
+}
+
+func (g *Game) create(event Event) {
+	settings := event.Settings
+	*g = Game{
+		ID:             event.GameID,
+		StartTime:      event.Time,
+		ResumeTime:     event.Time,
+		LastMoveTime:   event.Time,
+		TimeLimit:      settings.TimeLimit,
+		Rows:           settings.Rows,
+		Cols:           settings.Cols,
+		Mines:          settings.Mines,
+		Preset:         settings.Preset,
+		Topology:       settings.Topology,
+		Wrap:           settings.Wrap,
+		MarkCycle:      settings.MarkCycle,
+		Seed:           settings.Seed,
+		FirstClickSafe: settings.FirstClickSafe,
+		SafeNeighbours: settings.SafeNeighbours,
+		NoGuess:        settings.NoGuess,
+		Practice:       settings.Practice,
+		RestartedFrom:  settings.RestartedFrom,
+		Status:         Running,
+		Grid:           NewGrid(settings.Rows, settings.Cols),
+	}
+	if event.Mines != nil {
+		g.layMines(event.Mines)
+		g.GenerationAttempts = event.GenerationAttempts
+		g.GenerationTime = event.GenerationTime
+	}
+}
+
+// layMines replaces the game mines by the given ones.
+func (g *Game) layMines(mines []Coordinate) {
+	for x := range g.Grid {
+		for y := range g.Grid[x] {
+			g.Grid[x][y].Mine = false
+			g.Grid[x][y].MinesAround = 0
+		}
+	}
+	for _, mine := range mines {
+		g.Grid[mine.Row][mine.Col].Mine = true
+	}
+	g.CountMinesAround()
+	g.MinesPlaced = true
+}
+
+// reveal reveals a hidden cell, cascading to its neighbours when it has no
+// mines around.
+func (g *Game) reveal(row, col int) {
+	g.Grid[row][col].Revealed = true
+	g.Grid[row][col].Mark = NoMark
+	g.CellsRevealed++
+
+	if !g.Grid[row][col].Mine && g.Grid[row][col].MinesAround == 0 {
+		g.revealAdjacentSquares(row, col)
+	}
+}
+
+// revealAdjacentSquares reveals the neighbours of an empty cell, cascading
+// through every revealed neighbour without mines around. It keeps its own
+// stack, so large open areas don't grow the goroutine one.
+func (g *Game) revealAdjacentSquares(row, col int) {
+	board := g.Board()
+	var neighbours []Coordinate
+	pending := []Coordinate{{Row: row, Col: col}}
+	for len(pending) > 0 {
+		current := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+
+		neighbours = board.AppendNeighbours(neighbours[:0], current)
+		for _, n := range neighbours {
+			cell := &g.Grid[n.Row][n.Col]
+			if cell.Revealed {
+				continue
+			}
+			if cell.Flagged() {
+				continue
+			}
+
+			cell.Revealed = true
+			cell.Mark = NoMark
+			g.CellsRevealed++
+			if !cell.Mine && cell.MinesAround == 0 {
+				pending = append(pending, n)
+			}
+		}
+	}
+}
+
+// chord reveals the hidden, unflagged neighbours of a revealed cell,
+// stopping on the first mine.
+func (g *Game) chord(row, col int) {
+	for _, n := range g.Neighbours(row, col) {
+		if g.Grid[n.Row][n.Col].Revealed || g.Grid[n.Row][n.Col].Flagged() {
+			continue
+		}
+		g.reveal(n.Row, n.Col)
+		if g.Grid[n.Row][n.Col].Mine {
+			return
+		}
+	}
+}
+
+// record adds a move to the game history once it is applied, so flag
+// moves keep the mark they left.
+func (g *Game) record(kind MoveKind, event Event) {
+	row, col := event.Cell.Row, event.Cell.Col
+	g.LastMoveTime = event.Time
+	g.Moves = append(g.Moves, Move{
+		Kind: kind,
+		Row:  row,
+		Col:  col,
+		Mark: g.Grid[row][col].Mark,
+		Time: event.Time,
+	})
+}
+
+// undo rolls back the last moves, playing the ones left again on a hidden
+// board, and puts a finished game back into play.
+func (g *Game) undo(event Event) {
+	moves := g.Moves[:len(g.Moves)-event.Moves]
+	for x := range g.Grid {
+		for y := range g.Grid[x] {
+			g.Grid[x][y].Revealed = false
+			g.Grid[x][y].Mark = NoMark
+		}
+	}
+	g.CellsRevealed = 0
+
+	for _, move := range moves {
+		switch move.Kind {
+		case RevealMove:
+			g.reveal(move.Row, move.Col)
+		case FlagMove:
+			g.Grid[move.Row][move.Col].Mark = move.Mark
+		case ChordMove:
+			g.chord(move.Row, move.Col)
+		}
+	}
+	g.Moves = moves
+	g.Undos += event.Moves
+	g.LastMoveTime = event.Time
+
+	if g.Status.Finished() {
+		g.FinishTime = time.Time{}
+		g.FinishReason = ""
+		g.EndedBy = ""
+		g.ResumeTime = event.Time
+		g.Status = Running
+	}
+}
+
+func (g *Game) end(event Event) {
+	g.FinishTime = event.Time
+	g.ActiveTime = g.Elapsed(event.Time)
+	if event.Reason == TimeExpired {
+		// The expiry may be noticed late, the game was only played up to
+		// its limit
+		g.ActiveTime = time.Duration(g.TimeLimit) * time.Second
+	}
+	g.Status = event.Reason.Status()
+	g.FinishReason = event.Reason
+	g.EndedBy = event.EndedBy
+}
@@ -0,0 +1,99 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProject(t *testing.T) {
+	start := time.Now()
+	created := Event{
+		Kind:     CreatedEvent,
+		GameID:   "3",
+		Time:     start,
+		Settings: &GameSettings{Rows: 1, Cols: 4, Mines: 1, Seed: 42},
+		Mines:    []Coordinate{{Row: 0, Col: 2}},
+	}
+	flagged := NewCellEvent(FlaggedEvent, 0, 3, start.Add(time.Second))
+	flagged.Mark = Flag
+
+	cases := []struct {
+		name     string
+		events   []Event
+		expected func(t *testing.T, game *Game)
+	}{
+		{
+			name:   "OK/CREATED",
+			events: []Event{created},
+			expected: func(t *testing.T, game *Game) {
+				assert.Equal(t, "3", game.ID)
+				assert.Equal(t, int64(42), game.Seed)
+				assert.Equal(t, Running, game.Status)
+				assert.Equal(t, start, game.StartTime)
+				assert.True(t, game.MinesPlaced)
+				assert.True(t, game.Grid[0][2].Mine)
+				assert.Equal(t, []uint8{0, 1, 0, 1}, []uint8{
+					game.Grid[0][0].MinesAround,
+					game.Grid[0][1].MinesAround,
+					game.Grid[0][2].MinesAround,
+					game.Grid[0][3].MinesAround,
+				})
+			},
+		},
+		{
+			name: "OK/MOVES",
+			events: []Event{
+				created,
+				flagged,
+				NewCellEvent(RevealedEvent, 0, 0, start.Add(2*time.Second)),
+			},
+			expected: func(t *testing.T, game *Game) {
+				assert.Equal(t, Flag, game.Grid[0][3].Mark)
+				assert.True(t, game.Grid[0][1].Revealed)
+				assert.Equal(t, 2, game.CellsRevealed)
+				assert.Equal(t, 2, len(game.Moves))
+				assert.Equal(t, start.Add(2*time.Second), game.LastMoveTime)
+			},
+		},
+		{
+			name: "OK/FINISHED",
+			events: []Event{
+				created,
+				NewCellEvent(RevealedEvent, 0, 2, start.Add(time.Minute)),
+				NewFinishedEvent(MineRevealed, PlayerActor, start.Add(time.Minute)),
+			},
+			expected: func(t *testing.T, game *Game) {
+				assert.Equal(t, Loose, game.Status)
+				assert.Equal(t, PlayerActor, game.EndedBy)
+				assert.Equal(t, time.Minute, game.ActiveTime)
+			},
+		},
+		{
+			name: "OK/UNDONE",
+			events: []Event{
+				created,
+				flagged,
+				NewCellEvent(RevealedEvent, 0, 2, start.Add(time.Minute)),
+				NewFinishedEvent(MineRevealed, PlayerActor, start.Add(time.Minute)),
+				{Kind: UndoneEvent, Time: start.Add(2 * time.Minute), Moves: 1},
+			},
+			expected: func(t *testing.T, game *Game) {
+				assert.Equal(t, Running, game.Status)
+				assert.False(t, game.Grid[0][2].Revealed)
+				assert.Equal(t, Flag, game.Grid[0][3].Mark)
+				assert.Equal(t, 1, game.Undos)
+				assert.Equal(t, time.Minute, game.ActiveTime)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			game := Project(c.events)
+			c.expected(t, game)
+			assert.Equal(t, len(c.events), game.Version)
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package model
+
+// FinishReason records what ended a game.
+type FinishReason string
+
+const (
+	BoardCleared   FinishReason = "board_cleared"
+	MineRevealed   FinishReason = "mine_revealed"
+	TimeExpired    FinishReason = "time_expired"
+	PlayerResigned FinishReason = "player_resigned"
+	IdleTimeout    FinishReason = "idle_timeout"
+)
+
+// Actor records who ended a game: the player with a move, or the server
+// on its own.
+type Actor string
+
+const (
+	PlayerActor Actor = "player"
+	ServerActor Actor = "server"
+)
+
+// Status returns the status a game ends with for the reason.
+func (r FinishReason) Status() GameStatus {
+	switch r {
+	case BoardCleared:
+		return Win
+	case PlayerResigned:
+		return Resigned
+	case IdleTimeout:
+		return Abandoned
+	default:
+		return Loose
+	}
+}
@@ -1,31 +1,183 @@
 package model
 
 import (
+	"errors"
 	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation"
 )
 
+const (
+	MaxSide = 1000
+	// MaxNoGuessSide keeps the no guess generation, that solves every
+	// candidate layout, within a request time.
+	MaxNoGuessSide = 50
+)
+
 type Game struct {
-	ID            int        `json:"id"`
-	StartTime     time.Time  `json:"start_time"`
-	FinishTime    time.Time  `json:"finish_time"`
-	Rows          int        `json:"rows"`
-	Cols          int        `json:"cols"`
-	Mines         int        `json:"mines"`
-	CellsRevealed int        `json:"cells_revealed"`
-	Status        GameStatus `json:"game_status"`
-	Grid          [][]Cell   `json:"grid,omitempty"`
+	ID                 string        `json:"id"`
+	Version            int           `json:"version"`
+	StartTime          time.Time     `json:"start_time"`
+	FinishTime         time.Time     `json:"finish_time"`
+	ResumeTime         time.Time     `json:"resume_time"`
+	LastMoveTime       time.Time     `json:"last_move_time"`
+	ActiveTime         time.Duration `json:"active_time"`
+	TimeLimit          int           `json:"time_limit,omitempty"`
+	Rows               int           `json:"rows"`
+	Cols               int           `json:"cols"`
+	Mines              int           `json:"mines"`
+	Preset             string        `json:"preset,omitempty"`
+	Topology           TopologyKind  `json:"topology,omitempty"`
+	Wrap               bool          `json:"wrap"`
+	MarkCycle          []Mark        `json:"mark_cycle,omitempty"`
+	Seed               int64         `json:"seed"`
+	FirstClickSafe     bool          `json:"first_click_safe"`
+	SafeNeighbours     bool          `json:"safe_neighbours"`
+	MinesPlaced        bool          `json:"mines_placed"`
+	NoGuess            bool          `json:"no_guess"`
+	Practice           bool          `json:"practice"`
+	RestartedFrom      string        `json:"restarted_from,omitempty"`
+	GenerationAttempts int           `json:"generation_attempts"`
+	GenerationTime     time.Duration `json:"generation_time"`
+	CellsRevealed      int           `json:"cells_revealed"`
+	Undos              int           `json:"undos"`
+	HintsUsed          int           `json:"hints_used"`
+	Moves              []Move        `json:"moves,omitempty"`
+	Status             GameStatus    `json:"game_status"`
+	FinishReason       FinishReason  `json:"finish_reason,omitempty"`
+	EndedBy            Actor         `json:"ended_by,omitempty"`
+	Grid               [][]Cell      `json:"grid,omitempty"`
 }
 
 func (g Game) Validate() error {
+	if g.Preset != "" {
+		return g.validatePreset()
+	}
+
+	return g.ValidateBoard()
+}
+
+// ValidateBoard checks the board shape and dimensions, also for games
+// whose dimensions come from a preset.
+func (g Game) ValidateBoard() error {
 	return validation.ValidateStruct(&g,
+		validation.Field(&g.Topology, validation.In(Square, Hex)),
 		validation.Field(&g.Rows, validation.Required, validation.Min(1)),
-		validation.Field(&g.Rows, validation.Required, validation.Max(50)),
+		validation.Field(&g.Rows, validation.Required, validation.Max(g.maxSide())),
+		validation.Field(&g.Rows, validation.By(g.evenRowsOnWrappedHex)),
+		validation.Field(&g.MarkCycle, validation.By(validMarkCycle)),
 		validation.Field(&g.Cols, validation.Required, validation.Min(1)),
-		validation.Field(&g.Cols, validation.Required, validation.Max(50)),
+		validation.Field(&g.Cols, validation.Required, validation.Max(g.maxSide())),
 		validation.Field(&g.Mines, validation.Required, validation.Min(1)),
-		//At least 1 empty cell
-		validation.Field(&g.Mines, validation.Required, validation.Max(g.Rows*g.Cols-1)),
+		validation.Field(&g.TimeLimit, validation.Min(0)),
+		//At least 1 empty cell, or room for the first click opening on no guess games
+		validation.Field(&g.Mines, validation.Required, validation.Max(g.maxMines())),
 	)
 }
+
+// Elapsed returns the time the game has been played up to now, leaving out
+// the time it spent paused.
+func (g *Game) Elapsed(now time.Time) time.Duration {
+	if g.Status == Running {
+		return g.ActiveTime + now.Sub(g.ResumeTime)
+	}
+	return g.ActiveTime
+}
+
+// Expired tells if a time limited game ran out of time. The limit counts
+// played time, in seconds.
+func (g *Game) Expired(now time.Time) bool {
+	return g.TimeLimit > 0 && g.Status == Running && g.Elapsed(now) >= time.Duration(g.TimeLimit)*time.Second
+}
+
+// Idle tells if an unfinished game got no moves for the given timeout.
+// A zero timeout never lets a game go idle.
+func (g *Game) Idle(now time.Time, timeout time.Duration) bool {
+	return timeout > 0 && !g.Status.Finished() && now.Sub(g.LastMoveTime) >= timeout
+}
+
+// CanUndoLoss tells if the game was lost on a mine in practice, so the
+// losing reveal can still be undone.
+func (g *Game) CanUndoLoss() bool {
+	return g.Practice && g.Status == Loose && g.FinishReason == MineRevealed
+}
+
+// LayoutShown tells if the game is over for good, so its mine layout
+// needs no hiding. Practice games lost on a mine may be played again.
+func (g *Game) LayoutShown() bool {
+	return g.Status.Finished() && !g.CanUndoLoss()
+}
+
+// Board returns the topology of the game board.
+func (g *Game) Board() Topology {
+	return NewTopology(g.Topology, g.Rows, g.Cols, g.Wrap)
+}
+
+// Contains tells if the cell is inside the game board.
+func (g *Game) Contains(row, col int) bool {
+	return g.Board().Contains(Coordinate{Row: row, Col: col})
+}
+
+// Neighbours returns the cells touching the given one on the game board.
+func (g *Game) Neighbours(row, col int) []Coordinate {
+	return g.Board().Neighbours(Coordinate{Row: row, Col: col})
+}
+
+// Copy returns a copy of the game that shares no cells nor moves with
+// it, so changing one leaves the other as it was.
+func (g *Game) Copy() *Game {
+	game := *g
+	if g.Grid != nil {
+		game.Grid = NewGrid(g.Rows, g.Cols)
+		for x := range g.Grid {
+			copy(game.Grid[x], g.Grid[x])
+		}
+	}
+	game.Moves = append([]Move(nil), g.Moves...)
+	game.MarkCycle = append([]Mark(nil), g.MarkCycle...)
+	return &game
+}
+
+// validatePreset checks a game asking for a preset, whose dimensions are
+// taken from the preset and must not be sent.
+func (g Game) validatePreset() error {
+	return validation.ValidateStruct(&g,
+		validation.Field(&g.Preset, validation.Length(1, 30), validation.Match(presetNameFormat)),
+		validation.Field(&g.Topology, validation.In(Square, Hex)),
+		validation.Field(&g.Rows, validation.By(blankWithPreset)),
+		validation.Field(&g.Cols, validation.By(blankWithPreset)),
+		validation.Field(&g.Mines, validation.By(blankWithPreset)),
+		validation.Field(&g.TimeLimit, validation.Min(0)),
+	)
+}
+
+// evenRowsOnWrappedHex checks hex boards wrap onto themselves: the shifted
+// odd rows only line up with the first row when there is an even number
+// of rows.
+func (g Game) evenRowsOnWrappedHex(value interface{}) error {
+	if g.Topology == Hex && g.Wrap && value.(int)%2 != 0 {
+		return errors.New("must be even on a wrapped hex board")
+	}
+	return nil
+}
+
+func blankWithPreset(value interface{}) error {
+	if value.(int) != 0 {
+		return errors.New("must be blank when using a preset")
+	}
+	return nil
+}
+
+func (g Game) maxSide() int {
+	if g.NoGuess {
+		return MaxNoGuessSide
+	}
+	return MaxSide
+}
+
+func (g Game) maxMines() int {
+	if g.NoGuess {
+		return g.Rows*g.Cols - 9
+	}
+	return g.Rows*g.Cols - 1
+}
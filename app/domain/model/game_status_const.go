@@ -6,8 +6,16 @@ const (
 	Win GameStatus = iota
 	Loose
 	Running
+	Paused
+	Resigned
+	Abandoned
 )
 
 func (s GameStatus) String() string {
-	return [...]string{"WIN", "LOOSE", "RUNNING"}[s]
+	return [...]string{"WIN", "LOOSE", "RUNNING", "PAUSED", "RESIGNED", "ABANDONED"}[s]
+}
+
+// Finished tells if the game is over, so nothing on it needs hiding.
+func (s GameStatus) Finished() bool {
+	return s != Running && s != Paused
 }
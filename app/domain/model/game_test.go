@@ -2,6 +2,7 @@ package model
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -32,11 +33,11 @@ func TestGame_Validate(t *testing.T) {
 		{
 			name: "FAIL/ROWS_GREATER_LIMIT",
 			game: Game{
-				Rows:  100,
+				Rows:  1001,
 				Cols:  10,
 				Mines: 1,
 			},
-			errText: "rows: must be no greater than 50.",
+			errText: "rows: must be no greater than 1000.",
 		},
 		{
 			name: "FAIL/COLS_MISSING",
@@ -50,10 +51,10 @@ func TestGame_Validate(t *testing.T) {
 			name: "FAIL/COLS_GREATER_LIMIT",
 			game: Game{
 				Rows:  10,
-				Cols:  100,
+				Cols:  1001,
 				Mines: 1,
 			},
-			errText: "cols: must be no greater than 50.",
+			errText: "cols: must be no greater than 1000.",
 		},
 		{
 			name: "FAIL/MINES_MISSING",
@@ -72,6 +73,89 @@ func TestGame_Validate(t *testing.T) {
 			},
 			errText: "mines: must be no greater than 99.",
 		},
+		{
+			name: "OK/HEX",
+			game: Game{
+				Rows:     10,
+				Cols:     10,
+				Mines:    1,
+				Topology: Hex,
+			},
+			errText: "",
+		},
+		{
+			name: "FAIL/UNKNOWN_TOPOLOGY",
+			game: Game{
+				Rows:     10,
+				Cols:     10,
+				Mines:    1,
+				Topology: "triangle",
+			},
+			errText: "topology: must be a valid value.",
+		},
+		{
+			name: "FAIL/WRAPPED_HEX_ODD_ROWS",
+			game: Game{
+				Rows:     9,
+				Cols:     10,
+				Mines:    1,
+				Topology: Hex,
+				Wrap:     true,
+			},
+			errText: "rows: must be even on a wrapped hex board.",
+		},
+		{
+			name: "FAIL/MARK_CYCLE_WITHOUT_NONE",
+			game: Game{
+				Rows:      10,
+				Cols:      10,
+				Mines:     1,
+				MarkCycle: []Mark{Flag, Question},
+			},
+			errText: "mark_cycle: must include none.",
+		},
+		{
+			name: "OK/PRESET",
+			game: Game{
+				Preset: "expert",
+			},
+			errText: "",
+		},
+		{
+			name: "FAIL/PRESET_WITH_DIMENSIONS",
+			game: Game{
+				Preset: "expert",
+				Rows:   10,
+			},
+			errText: "rows: must be blank when using a preset.",
+		},
+		{
+			name: "FAIL/PRESET_INVALID_NAME",
+			game: Game{
+				Preset: "Expert Board",
+			},
+			errText: "preset: must be in a valid format.",
+		},
+		{
+			name: "FAIL/NO_GUESS_ROWS_GREATER_LIMIT",
+			game: Game{
+				Rows:    100,
+				Cols:    10,
+				Mines:   1,
+				NoGuess: true,
+			},
+			errText: "rows: must be no greater than 50.",
+		},
+		{
+			name: "FAIL/NO_GUESS_MINES_GREATER_LIMIT",
+			game: Game{
+				Rows:    10,
+				Cols:    10,
+				Mines:   92,
+				NoGuess: true,
+			},
+			errText: "mines: must be no greater than 91.",
+		},
 	}
 
 	for _, c := range cases {
@@ -84,3 +168,109 @@ func TestGame_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestGame_Elapsed(t *testing.T) {
+	now := time.Now()
+	cases := []struct {
+		name    string
+		game    Game
+		elapsed time.Duration
+	}{
+		{
+			name: "OK/RUNNING",
+			game: Game{
+				ResumeTime: now.Add(-time.Minute),
+				ActiveTime: time.Hour,
+				Status:     Running,
+			},
+			elapsed: time.Hour + time.Minute,
+		},
+		{
+			name: "OK/PAUSED",
+			game: Game{
+				ResumeTime: now.Add(-time.Minute),
+				ActiveTime: time.Hour,
+				Status:     Paused,
+			},
+			elapsed: time.Hour,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.elapsed, c.game.Elapsed(now))
+		})
+	}
+}
+
+func TestGame_Expired(t *testing.T) {
+	now := time.Now()
+	cases := []struct {
+		name    string
+		game    Game
+		expired bool
+	}{
+		{
+			name: "OK/NO_TIME_LIMIT",
+			game: Game{
+				ResumeTime: now.Add(-time.Hour),
+				Status:     Running,
+			},
+			expired: false,
+		},
+		{
+			name: "OK/WITHIN_TIME_LIMIT",
+			game: Game{
+				ResumeTime: now.Add(-time.Minute),
+				TimeLimit:  61,
+				Status:     Running,
+			},
+			expired: false,
+		},
+		{
+			name: "OK/TIME_LIMIT_REACHED",
+			game: Game{
+				ResumeTime: now.Add(-time.Minute),
+				TimeLimit:  60,
+				Status:     Running,
+			},
+			expired: true,
+		},
+		{
+			name: "OK/FINISHED",
+			game: Game{
+				ActiveTime: time.Hour,
+				TimeLimit:  60,
+				Status:     Win,
+			},
+			expired: false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.expired, c.game.Expired(now))
+		})
+	}
+}
+
+func TestGame_Copy(t *testing.T) {
+	game := &Game{
+		ID:        "1",
+		Rows:      1,
+		Cols:      2,
+		MarkCycle: []Mark{NoMark, Flag},
+		Moves:     []Move{{Kind: RevealMove}},
+		Grid:      NewGrid(1, 2),
+	}
+
+	copied := game.Copy()
+	assert.Equal(t, game, copied)
+
+	copied.Grid[0][1].Revealed = true
+	copied.Moves[0].Kind = FlagMove
+	copied.MarkCycle[1] = Question
+	assert.False(t, game.Grid[0][1].Revealed)
+	assert.Equal(t, RevealMove, game.Moves[0].Kind)
+	assert.Equal(t, Flag, game.MarkCycle[1])
+}
@@ -0,0 +1,68 @@
+package model
+
+import (
+	"errors"
+)
+
+type Mark uint8
+
+const (
+	NoMark Mark = iota
+	Flag
+	Question
+)
+
+var markNames = [...]string{"none", "flag", "question"}
+
+// DefaultMarkCycle is the classic flag toggle, without question marks.
+var DefaultMarkCycle = []Mark{NoMark, Flag}
+
+func (m Mark) String() string {
+	return markNames[m]
+}
+
+func (m Mark) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+func (m *Mark) UnmarshalText(text []byte) error {
+	for i, name := range markNames {
+		if name == string(text) {
+			*m = Mark(i)
+			return nil
+		}
+	}
+	return errors.New("unknown mark " + string(text))
+}
+
+// NextMark returns the mark following the current one in the cycle.
+func NextMark(cycle []Mark, current Mark) Mark {
+	if len(cycle) == 0 {
+		cycle = DefaultMarkCycle
+	}
+	for i, mark := range cycle {
+		if mark == current {
+			return cycle[(i+1)%len(cycle)]
+		}
+	}
+	return cycle[0]
+}
+
+func validMarkCycle(value interface{}) error {
+	cycle := value.([]Mark)
+	if len(cycle) == 0 {
+		return nil
+	}
+
+	seen := map[Mark]bool{}
+	for _, mark := range cycle {
+		if seen[mark] {
+			return errors.New("must not repeat marks")
+		}
+		seen[mark] = true
+	}
+	if !seen[NoMark] {
+		return errors.New("must include none")
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextMark(t *testing.T) {
+	cases := []struct {
+		name    string
+		cycle   []Mark
+		current Mark
+		next    Mark
+	}{
+		{
+			name:    "OK/DEFAULT_FLAG",
+			cycle:   nil,
+			current: NoMark,
+			next:    Flag,
+		},
+		{
+			name:    "OK/DEFAULT_UNFLAG",
+			cycle:   nil,
+			current: Flag,
+			next:    NoMark,
+		},
+		{
+			name:    "OK/QUESTION_AFTER_FLAG",
+			cycle:   []Mark{NoMark, Flag, Question},
+			current: Flag,
+			next:    Question,
+		},
+		{
+			name:    "OK/QUESTION_BEFORE_FLAG",
+			cycle:   []Mark{NoMark, Question, Flag},
+			current: Flag,
+			next:    NoMark,
+		},
+		{
+			name:    "OK/MARK_OUT_OF_CYCLE",
+			cycle:   []Mark{NoMark, Flag},
+			current: Question,
+			next:    NoMark,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.next, NextMark(c.cycle, c.current))
+		})
+	}
+}
+
+func TestMarkJSON(t *testing.T) {
+	data, err := json.Marshal(Cell{Mark: Question})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"mine":false,"revealed":false,"mark":"question","mines_around":0}`, string(data))
+
+	var game Game
+	err = json.Unmarshal([]byte(`{"mark_cycle":["none","question","flag"]}`), &game)
+	assert.Nil(t, err)
+	assert.Equal(t, []Mark{NoMark, Question, Flag}, game.MarkCycle)
+
+	err = json.Unmarshal([]byte(`{"mark_cycle":["maybe"]}`), &game)
+	assert.NotNil(t, err)
+}
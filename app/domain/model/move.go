@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+type MoveKind string
+
+const (
+	RevealMove MoveKind = "reveal"
+	FlagMove   MoveKind = "flag"
+	ChordMove  MoveKind = "chord"
+)
+
+// Move is a player move on a game cell. Flag moves keep the mark they
+// left on the cell, so the history can be played again as it happened.
+type Move struct {
+	Kind MoveKind  `json:"kind"`
+	Row  int       `json:"row"`
+	Col  int       `json:"col"`
+	Mark Mark      `json:"mark,omitempty"`
+	Time time.Time `json:"time"`
+}
@@ -0,0 +1,65 @@
+package model
+
+import (
+	"errors"
+	"regexp"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+var presetNameFormat = regexp.MustCompile("^[a-z0-9_-]+$")
+
+type Preset struct {
+	Name  string `json:"name"`
+	Rows  int    `json:"rows"`
+	Cols  int    `json:"cols"`
+	Mines int    `json:"mines"`
+}
+
+var builtinPresets = map[string]Preset{
+	"beginner":     {Name: "beginner", Rows: 9, Cols: 9, Mines: 10},
+	"intermediate": {Name: "intermediate", Rows: 16, Cols: 16, Mines: 40},
+	"expert":       {Name: "expert", Rows: 16, Cols: 30, Mines: 99},
+}
+
+// BuiltinPreset returns one of the standard difficulty presets.
+func BuiltinPreset(name string) (Preset, bool) {
+	preset, exists := builtinPresets[name]
+	return preset, exists
+}
+
+// BuiltinPresets returns the standard difficulty presets.
+func BuiltinPresets() []Preset {
+	return []Preset{
+		builtinPresets["beginner"],
+		builtinPresets["intermediate"],
+		builtinPresets["expert"],
+	}
+}
+
+func (p Preset) Validate() error {
+	err := validation.ValidateStruct(&p,
+		validation.Field(&p.Name, validation.Required, validation.Length(1, 30), validation.Match(presetNameFormat)),
+		validation.Field(&p.Name, validation.By(notBuiltin)),
+	)
+	if err != nil {
+		return err
+	}
+
+	return Game{Rows: p.Rows, Cols: p.Cols, Mines: p.Mines}.ValidateBoard()
+}
+
+// Apply sets the preset dimensions on the game.
+func (p Preset) Apply(game *Game) {
+	game.Preset = p.Name
+	game.Rows = p.Rows
+	game.Cols = p.Cols
+	game.Mines = p.Mines
+}
+
+func notBuiltin(value interface{}) error {
+	if _, exists := BuiltinPreset(value.(string)); exists {
+		return errors.New("is a builtin preset")
+	}
+	return nil
+}
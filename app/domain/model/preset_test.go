@@ -0,0 +1,66 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreset_Validate(t *testing.T) {
+	cases := []struct {
+		name    string
+		preset  Preset
+		errText string
+	}{
+		{
+			name: "OK",
+			preset: Preset{
+				Name:  "tiny",
+				Rows:  5,
+				Cols:  5,
+				Mines: 3,
+			},
+			errText: "",
+		},
+		{
+			name: "FAIL/NAME_MISSING",
+			preset: Preset{
+				Rows:  5,
+				Cols:  5,
+				Mines: 3,
+			},
+			errText: "name: cannot be blank.",
+		},
+		{
+			name: "FAIL/BUILTIN_NAME",
+			preset: Preset{
+				Name:  "expert",
+				Rows:  5,
+				Cols:  5,
+				Mines: 3,
+			},
+			errText: "name: is a builtin preset.",
+		},
+		{
+			name: "FAIL/MINES_GREATER_LIMIT",
+			preset: Preset{
+				Name:  "tiny",
+				Rows:  5,
+				Cols:  5,
+				Mines: 25,
+			},
+			errText: "mines: must be no greater than 24.",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.preset.Validate()
+			if c.errText != "" {
+				assert.Equal(t, c.errText, err.Error())
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package model
+
+// Replay rebuilds a game step by step from its events. Every move and
+// every undo is a step, step 0 being the game as it was created.
+type Replay struct {
+	Step  int
+	Steps int
+	// Event is the event of the current step, nil on step 0.
+	Event *Event
+	Game  *Game
+
+	events []Event
+	next   int
+}
+
+func NewReplay(events []Event) *Replay {
+	replay := &Replay{
+		Game:   &Game{},
+		events: events,
+	}
+	for _, event := range events {
+		if isStep(event) {
+			replay.Steps++
+		}
+	}
+	if len(events) > 0 {
+		replay.Game.Apply(events[0])
+		replay.next = 1
+	}
+	return replay
+}
+
+// Next moves the replay to the following step, applying every event up to
+// it and the finish it caused, if any. It tells if there was a step left.
+func (r *Replay) Next() bool {
+	if r.Step == r.Steps {
+		return false
+	}
+
+	for !isStep(r.events[r.next]) {
+		r.Game.Apply(r.events[r.next])
+		r.next++
+	}
+	r.Event = &r.events[r.next]
+	r.Game.Apply(*r.Event)
+	r.next++
+	for r.next < len(r.events) && r.events[r.next].Kind == FinishedEvent {
+		r.Game.Apply(r.events[r.next])
+		r.next++
+	}
+	r.Step++
+	return true
+}
+
+func isStep(event Event) bool {
+	switch event.Kind {
+	case RevealedEvent, FlaggedEvent, ChordedEvent, UndoneEvent:
+		return true
+	}
+	return false
+}
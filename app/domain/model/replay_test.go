@@ -0,0 +1,75 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplay(t *testing.T) {
+	start := time.Now()
+	events := []Event{
+		{
+			Kind:     CreatedEvent,
+			Time:     start,
+			Settings: &GameSettings{Rows: 1, Cols: 4, Mines: 1, FirstClickSafe: true},
+		},
+		{
+			Kind:  MinesPlacedEvent,
+			Time:  start.Add(time.Second),
+			Mines: []Coordinate{{Row: 0, Col: 2}},
+		},
+		NewCellEvent(RevealedEvent, 0, 0, start.Add(time.Second)),
+		{Kind: PausedEvent, Time: start.Add(2 * time.Second)},
+		{Kind: ResumedEvent, Time: start.Add(3 * time.Second)},
+		NewCellEvent(RevealedEvent, 0, 2, start.Add(4*time.Second)),
+		NewFinishedEvent(MineRevealed, PlayerActor, start.Add(4*time.Second)),
+		{Kind: UndoneEvent, Time: start.Add(5 * time.Second), Moves: 1},
+	}
+
+	replay := NewReplay(events)
+	assert.Equal(t, 0, replay.Step)
+	assert.Equal(t, 3, replay.Steps)
+	assert.Nil(t, replay.Event)
+	assert.False(t, replay.Game.MinesPlaced)
+
+	cases := []struct {
+		name     string
+		kind     EventKind
+		status   GameStatus
+		revealed int
+	}{
+		{
+			name:     "OK/FIRST_REVEAL",
+			kind:     RevealedEvent,
+			status:   Running,
+			revealed: 2,
+		},
+		{
+			name:     "OK/LOSING_REVEAL",
+			kind:     RevealedEvent,
+			status:   Loose,
+			revealed: 3,
+		},
+		{
+			name:     "OK/UNDO",
+			kind:     UndoneEvent,
+			status:   Running,
+			revealed: 2,
+		},
+	}
+
+	for i, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.True(t, replay.Next())
+			assert.Equal(t, i+1, replay.Step)
+			assert.Equal(t, c.kind, replay.Event.Kind)
+			assert.Equal(t, c.status, replay.Game.Status)
+			assert.Equal(t, c.revealed, replay.Game.CellsRevealed)
+		})
+	}
+
+	assert.False(t, replay.Next())
+	assert.Equal(t, 3, replay.Step)
+}
@@ -0,0 +1,127 @@
+package model
+
+type TopologyKind string
+
+const (
+	Square TopologyKind = "square"
+	Hex    TopologyKind = "hex"
+)
+
+type Coordinate struct {
+	Row int `json:"row"`
+	Col int `json:"col"`
+}
+
+// Topology owns the shape of a board: which coordinates are inside it and
+// which cells neighbour each other.
+type Topology interface {
+	Contains(c Coordinate) bool
+	Neighbours(c Coordinate) []Coordinate
+	// AppendNeighbours appends the neighbours of c to dst, so hot loops
+	// can reuse a buffer.
+	AppendNeighbours(dst []Coordinate, c Coordinate) []Coordinate
+}
+
+// NewTopology returns the topology of a rows x cols board. An empty kind
+// is a square board. On wrapped boards the edges join the opposite ones,
+// so there are no edge cells.
+func NewTopology(kind TopologyKind, rows, cols int, wrap bool) Topology {
+	bounds := bounds{rows: rows, cols: cols, wrap: wrap}
+	if kind == Hex {
+		return hexTopology{bounds}
+	}
+	return squareTopology{bounds}
+}
+
+type bounds struct {
+	rows int
+	cols int
+	wrap bool
+}
+
+func (b bounds) Contains(c Coordinate) bool {
+	return c.Row >= 0 && c.Row < b.rows && c.Col >= 0 && c.Col < b.cols
+}
+
+func (b bounds) appendInside(dst []Coordinate, c Coordinate, offsets [][2]int) []Coordinate {
+	start := len(dst)
+	for _, offset := range offsets {
+		n := Coordinate{Row: c.Row + offset[0], Col: c.Col + offset[1]}
+		if b.wrap {
+			n = b.wrapped(n)
+			// Narrow wrapped boards reach the same cell from both sides
+			if n == c || containsCoordinate(dst[start:], n) {
+				continue
+			}
+		}
+		if b.Contains(n) {
+			dst = append(dst, n)
+		}
+	}
+	return dst
+}
+
+func (b bounds) wrapped(c Coordinate) Coordinate {
+	return Coordinate{
+		Row: (c.Row%b.rows + b.rows) % b.rows,
+		Col: (c.Col%b.cols + b.cols) % b.cols,
+	}
+}
+
+func containsCoordinate(coordinates []Coordinate, c Coordinate) bool {
+	for _, coordinate := range coordinates {
+		if coordinate == c {
+			return true
+		}
+	}
+	return false
+}
+
+var squareOffsets = [][2]int{
+	{-1, -1}, {-1, 0}, {-1, 1},
+	{0, -1}, {0, 1},
+	{1, -1}, {1, 0}, {1, 1},
+}
+
+// squareTopology is the classic board where every cell touches 8 others.
+type squareTopology struct {
+	bounds
+}
+
+func (s squareTopology) Neighbours(c Coordinate) []Coordinate {
+	return s.AppendNeighbours(make([]Coordinate, 0, len(squareOffsets)), c)
+}
+
+func (s squareTopology) AppendNeighbours(dst []Coordinate, c Coordinate) []Coordinate {
+	return s.appendInside(dst, c, squareOffsets)
+}
+
+var (
+	hexEvenRowOffsets = [][2]int{
+		{-1, -1}, {-1, 0},
+		{0, -1}, {0, 1},
+		{1, -1}, {1, 0},
+	}
+	hexOddRowOffsets = [][2]int{
+		{-1, 0}, {-1, 1},
+		{0, -1}, {0, 1},
+		{1, 0}, {1, 1},
+	}
+)
+
+// hexTopology is a board of pointy hexagons where odd rows are shifted
+// half a cell to the right, so every cell touches 6 others.
+type hexTopology struct {
+	bounds
+}
+
+func (h hexTopology) Neighbours(c Coordinate) []Coordinate {
+	return h.AppendNeighbours(make([]Coordinate, 0, len(hexEvenRowOffsets)), c)
+}
+
+func (h hexTopology) AppendNeighbours(dst []Coordinate, c Coordinate) []Coordinate {
+	if c.Row%2 == 0 {
+		return h.appendInside(dst, c, hexEvenRowOffsets)
+	}
+	return h.appendInside(dst, c, hexOddRowOffsets)
+}
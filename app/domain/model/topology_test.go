@@ -0,0 +1,120 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTopologyNeighbours(t *testing.T) {
+	cases := []struct {
+		name       string
+		kind       TopologyKind
+		cell       Coordinate
+		neighbours []Coordinate
+	}{
+		{
+			name:       "OK/SQUARE_CORNER",
+			kind:       Square,
+			cell:       Coordinate{Row: 0, Col: 0},
+			neighbours: []Coordinate{{0, 1}, {1, 0}, {1, 1}},
+		},
+		{
+			name: "OK/SQUARE_MIDDLE",
+			kind: "",
+			cell: Coordinate{Row: 1, Col: 1},
+			neighbours: []Coordinate{
+				{0, 0}, {0, 1}, {0, 2},
+				{1, 0}, {1, 2},
+				{2, 0}, {2, 1}, {2, 2},
+			},
+		},
+		{
+			name: "OK/HEX_EVEN_ROW",
+			kind: Hex,
+			cell: Coordinate{Row: 2, Col: 1},
+			neighbours: []Coordinate{
+				{1, 0}, {1, 1},
+				{2, 0}, {2, 2},
+				{3, 0}, {3, 1},
+			},
+		},
+		{
+			name: "OK/HEX_ODD_ROW",
+			kind: Hex,
+			cell: Coordinate{Row: 1, Col: 1},
+			neighbours: []Coordinate{
+				{0, 1}, {0, 2},
+				{1, 0}, {1, 2},
+				{2, 1}, {2, 2},
+			},
+		},
+		{
+			name:       "OK/HEX_CORNER",
+			kind:       Hex,
+			cell:       Coordinate{Row: 0, Col: 0},
+			neighbours: []Coordinate{{0, 1}, {1, 0}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			topology := NewTopology(c.kind, 4, 3, false)
+			assert.True(t, topology.Contains(c.cell))
+			assert.Equal(t, c.neighbours, topology.Neighbours(c.cell))
+		})
+	}
+}
+
+func TestTopologyNeighboursWrap(t *testing.T) {
+	cases := []struct {
+		name       string
+		kind       TopologyKind
+		rows       int
+		cols       int
+		cell       Coordinate
+		neighbours []Coordinate
+	}{
+		{
+			name: "OK/SQUARE_CORNER",
+			kind: Square,
+			rows: 4,
+			cols: 3,
+			cell: Coordinate{Row: 0, Col: 0},
+			neighbours: []Coordinate{
+				{3, 2}, {3, 0}, {3, 1},
+				{0, 2}, {0, 1},
+				{1, 2}, {1, 0}, {1, 1},
+			},
+		},
+		{
+			name: "OK/SQUARE_NARROW",
+			kind: Square,
+			rows: 1,
+			cols: 2,
+			cell: Coordinate{Row: 0, Col: 0},
+			neighbours: []Coordinate{
+				{0, 1},
+			},
+		},
+		{
+			name: "OK/HEX_LAST_ROW",
+			kind: Hex,
+			rows: 4,
+			cols: 3,
+			cell: Coordinate{Row: 3, Col: 2},
+			neighbours: []Coordinate{
+				{2, 2}, {2, 0},
+				{3, 1}, {3, 0},
+				{0, 2}, {0, 0},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			topology := NewTopology(c.kind, c.rows, c.cols, true)
+			assert.Equal(t, c.neighbours, topology.Neighbours(c.cell))
+		})
+	}
+}
@@ -1,12 +1,36 @@
 package repository
 
 import (
+	"fmt"
+	"net/http"
+
 	"github.com/egorkos/minesweeper/app/domain/model"
 	"github.com/egorkos/minesweeper/app/interface/apierr"
 )
 
+const GameVersionConflict = "Game was changed by another request, current version is %d"
+
 type GameRepository interface {
 	FindAll() ([]*model.Game, *apierr.ApiError)
-	FindByID(ID int) (*model.Game, *apierr.ApiError)
-	Upsert(*model.Game) *apierr.ApiError
+	// FindUnfinished returns the running and paused games.
+	FindUnfinished() ([]*model.Game, *apierr.ApiError)
+	FindByID(ID string) (*model.Game, *apierr.ApiError)
+	FindEvents(ID string) ([]model.Event, *apierr.ApiError)
+	// Append stores new events for a game along with the game they were
+	// applied to. New games get their ID on their first append. The game
+	// must have been read at the version stored before the events, else
+	// nothing is stored and a conflict is returned.
+	Append(game *model.Game, events ...model.Event) *apierr.ApiError
+	// Update runs the update on the stored game holding a lock on it, so
+	// no other change of the game runs in between, and stores the events
+	// it returns. Nothing is stored when the update fails.
+	Update(ID string, update func(game *model.Game) ([]model.Event, *apierr.ApiError)) (*model.Game, *apierr.ApiError)
+}
+
+// NewVersionConflict is the error for an append on a game that was
+// changed since it was read.
+func NewVersionConflict(current int) *apierr.ApiError {
+	err := apierr.NewAPIError(fmt.Sprintf(GameVersionConflict, current), http.StatusConflict)
+	err.Version = &current
+	return err
 }
@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"github.com/egorkos/minesweeper/app/domain/model"
+	"github.com/egorkos/minesweeper/app/interface/apierr"
+)
+
+type PresetRepository interface {
+	FindAll() ([]*model.Preset, *apierr.ApiError)
+	FindByName(name string) (*model.Preset, *apierr.ApiError)
+	Upsert(*model.Preset) *apierr.ApiError
+}
@@ -2,10 +2,19 @@ package service
 
 import (
 	"math/rand"
+	"net/http"
 	"time"
 
 	"github.com/egorkos/minesweeper/app/domain/model"
 	"github.com/egorkos/minesweeper/app/domain/repository"
+	"github.com/egorkos/minesweeper/app/domain/solver"
+	"github.com/egorkos/minesweeper/app/interface/apierr"
+)
+
+const (
+	CantGenerateANoGuessBoard = "Can't generate a no guess board, try with fewer mines"
+
+	maxNoGuessAttempts = 1000
 )
 
 type GameService struct {
@@ -20,54 +29,140 @@ func NewGameService(repo repository.GameRepository) *GameService {
 
 func (g *GameService) StartGame(game model.Game) model.Game {
 	game.StartTime = time.Now()
+	game.ResumeTime = game.StartTime
+	game.LastMoveTime = game.StartTime
+	game.ActiveTime = 0
 	game.Status = model.Running
+	if game.Seed == 0 {
+		game.Seed = time.Now().UnixNano()
+	}
+	if game.NoGuess {
+		game.FirstClickSafe = true
+		game.SafeNeighbours = true
+	}
+	game.MinesPlaced = false
+	// Only restarts link a game to its source
+	game.RestartedFrom = ""
+	// Only placing the mines tells how long generating them took
+	game.GenerationAttempts = 0
+	game.GenerationTime = 0
 	createGrid(&game)
+	if !game.FirstClickSafe {
+		placeMines(&game, rand.New(rand.NewSource(game.Seed)), func(row, col int) bool { return false })
+	}
+	return game
+}
+
+// RestartGame starts a new game on the board of the given one, with every
+// cell hidden again. Games whose mines are not placed yet share their
+// settings and seed, the layout comes with the first reveal as usual.
+func (g *GameService) RestartGame(source *model.Game) model.Game {
+	game := g.StartGame(model.Game{
+		TimeLimit:      source.TimeLimit,
+		Rows:           source.Rows,
+		Cols:           source.Cols,
+		Mines:          source.Mines,
+		Preset:         source.Preset,
+		Topology:       source.Topology,
+		Wrap:           source.Wrap,
+		MarkCycle:      source.MarkCycle,
+		Seed:           source.Seed,
+		FirstClickSafe: source.FirstClickSafe,
+		SafeNeighbours: source.SafeNeighbours,
+		NoGuess:        source.NoGuess,
+		Practice:       source.Practice,
+	})
+	game.RestartedFrom = source.ID
+	if source.MinesPlaced {
+		copyMines(&game, source)
+	}
 	return game
 }
 
+// PlaceMines places the mines of a first click safe game, keeping the
+// clicked cell (and its neighbours when SafeNeighbours is set) mine-free.
+// No guess games keep generating layouts until one can be solved by
+// deduction from the clicked cell.
+func (g *GameService) PlaceMines(game *model.Game, row, col int) *apierr.ApiError {
+	safe := map[model.Coordinate]bool{{Row: row, Col: col}: true}
+	neighbours := game.Neighbours(row, col)
+	if game.SafeNeighbours && game.Rows*game.Cols-len(neighbours)-1 >= game.Mines {
+		for _, n := range neighbours {
+			safe[n] = true
+		}
+	}
+	safeZone := func(x, y int) bool {
+		return safe[model.Coordinate{Row: x, Col: y}]
+	}
+	rnd := rand.New(rand.NewSource(game.Seed))
+	if !game.NoGuess {
+		placeMines(game, rnd, safeZone)
+		return nil
+	}
+
+	start := time.Now()
+	defer func() {
+		game.GenerationTime = time.Since(start)
+	}()
+	for game.GenerationAttempts = 1; game.GenerationAttempts <= maxNoGuessAttempts; game.GenerationAttempts++ {
+		clearMines(game)
+		placeMines(game, rnd, safeZone)
+		if solver.Solvable(game, row, col) {
+			return nil
+		}
+	}
+
+	game.GenerationAttempts = maxNoGuessAttempts
+	clearMines(game)
+	return apierr.NewAPIError(CantGenerateANoGuessBoard, http.StatusBadRequest)
+}
+
 func createGrid(game *model.Game) {
-	game.Grid = make([][]model.Cell, game.Rows)
+	game.Grid = model.NewGrid(game.Rows, game.Cols)
+}
+
+func copyMines(game, source *model.Game) {
+	for x := range source.Grid {
+		for y := range source.Grid[x] {
+			game.Grid[x][y].Mine = source.Grid[x][y].Mine
+			game.Grid[x][y].MinesAround = source.Grid[x][y].MinesAround
+		}
+	}
+	game.MinesPlaced = true
+	game.GenerationAttempts = source.GenerationAttempts
+	game.GenerationTime = source.GenerationTime
+}
 
-	for i := range game.Grid {
-		game.Grid[i] = make([]model.Cell, game.Cols)
+func clearMines(game *model.Game) {
+	for x := range game.Grid {
+		for y := range game.Grid[x] {
+			game.Grid[x][y].Mine = false
+			game.Grid[x][y].MinesAround = 0
+		}
 	}
+	game.MinesPlaced = false
+}
 
-	setMines(game)
-	setMineIndicatorsAroundCell(game)
+func placeMines(game *model.Game, rnd *rand.Rand, safeZone func(row, col int) bool) {
+	setMines(game, rnd, safeZone)
+	game.CountMinesAround()
+	game.MinesPlaced = true
 }
 
-func setMines(game *model.Game) {
-	rand.Seed(time.Now().UnixNano())
+// setMines places the game mines using the given source, so the same
+// seed and dimensions always produce the same layout. Cells in the safe
+// zone never get a mine.
+func setMines(game *model.Game, rnd *rand.Rand, safeZone func(row, col int) bool) {
 	i := 0
 	for i < game.Mines {
-		x := rand.Intn(game.Rows)
-		y := rand.Intn(game.Cols)
+		x := rnd.Intn(game.Rows)
+		y := rnd.Intn(game.Cols)
+		if safeZone(x, y) {
+			continue
+		}
 		if !game.Grid[x][y].Mine {
 			game.Grid[x][y].Mine = true
 			i++
 		}
 	}
 }
-
-func setMineIndicatorsAroundCell(game *model.Game) {
-	for x := 0; x < game.Rows; x++ {
-		for y := 0; y < game.Cols; y++ {
-			if game.Grid[x][y].Mine {
-				for z := x - 1; z < x+2; z++ {
-					if z < 0 || z > game.Rows-1 {
-						continue
-					}
-					for w := y - 1; w < y+2; w++ {
-						if w < 0 || w > game.Cols-1 {
-							continue
-						}
-						if z == x && w == y {
-							continue
-						}
-						game.Grid[z][w].MinesAround++
-					}
-				}
-			}
-		}
-	}
-}
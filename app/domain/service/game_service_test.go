@@ -2,8 +2,10 @@ package service
 
 import (
 	"testing"
+	"time"
 
 	"github.com/egorkos/minesweeper/app/domain/model"
+	"github.com/egorkos/minesweeper/app/domain/solver"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -28,6 +30,25 @@ func TestGameService(t *testing.T) {
 				Mines: 5,
 			},
 		},
+		{
+			name: "OK/GENERATION_STATS_IGNORED",
+			game: model.Game{
+				Rows:               10,
+				Cols:               10,
+				Mines:              5,
+				GenerationAttempts: 777,
+				GenerationTime:     time.Hour,
+			},
+		},
+		{
+			name: "OK/RESTARTED_FROM_IGNORED",
+			game: model.Game{
+				Rows:          10,
+				Cols:          10,
+				Mines:         5,
+				RestartedFrom: "999",
+			},
+		},
 	}
 
 	gameService := &GameService{
@@ -45,6 +66,317 @@ func TestGameService(t *testing.T) {
 				}
 			}
 			assert.Equal(t, mines, c.game.Mines)
+			assert.Equal(t, 0, newGame.GenerationAttempts)
+			assert.Equal(t, time.Duration(0), newGame.GenerationTime)
+			assert.Empty(t, newGame.RestartedFrom)
+		})
+	}
+}
+
+func TestGameServiceMinesAround(t *testing.T) {
+	cases := []struct {
+		name          string
+		game          model.Game
+		maxNeighbours int
+	}{
+		{
+			name: "OK/SQUARE",
+			game: model.Game{
+				Rows:  10,
+				Cols:  10,
+				Mines: 30,
+			},
+			maxNeighbours: 8,
+		},
+		{
+			name: "OK/HEX",
+			game: model.Game{
+				Rows:     10,
+				Cols:     10,
+				Mines:    30,
+				Topology: model.Hex,
+			},
+			maxNeighbours: 6,
+		},
+	}
+
+	gameService := &GameService{
+		repo: nil,
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			newGame := gameService.StartGame(c.game)
+			for x := 0; x < newGame.Rows; x++ {
+				for y := 0; y < newGame.Cols; y++ {
+					neighbours := newGame.Neighbours(x, y)
+					assert.True(t, len(neighbours) <= c.maxNeighbours)
+
+					mines := 0
+					for _, n := range neighbours {
+						if newGame.Grid[n.Row][n.Col].Mine {
+							mines++
+						}
+					}
+					assert.Equal(t, uint8(mines), newGame.Grid[x][y].MinesAround)
+				}
+			}
+		})
+	}
+}
+
+func TestGameServiceSeed(t *testing.T) {
+	cases := []struct {
+		name string
+		game model.Game
+	}{
+		{
+			name: "OK/SAME_SEED_SAME_LAYOUT",
+			game: model.Game{
+				Rows:  10,
+				Cols:  10,
+				Mines: 20,
+				Seed:  42,
+			},
+		},
+		{
+			name: "OK/GENERATED_SEED",
+			game: model.Game{
+				Rows:  10,
+				Cols:  10,
+				Mines: 20,
+			},
+		},
+	}
+
+	gameService := &GameService{
+		repo: nil,
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			firstGame := gameService.StartGame(c.game)
+			assert.NotEqual(t, int64(0), firstGame.Seed)
+
+			c.game.Seed = firstGame.Seed
+			secondGame := gameService.StartGame(c.game)
+			assert.Equal(t, firstGame.Grid, secondGame.Grid)
+		})
+	}
+}
+
+func TestGameServicePlaceMines(t *testing.T) {
+	cases := []struct {
+		name     string
+		game     model.Game
+		row      int
+		col      int
+		safeSize int
+	}{
+		{
+			name: "OK/CLICKED_CELL",
+			game: model.Game{
+				Rows:           3,
+				Cols:           3,
+				Mines:          8,
+				FirstClickSafe: true,
+			},
+			row:      1,
+			col:      1,
+			safeSize: 0,
+		},
+		{
+			name: "OK/SAFE_NEIGHBOURS",
+			game: model.Game{
+				Rows:           10,
+				Cols:           10,
+				Mines:          91,
+				FirstClickSafe: true,
+				SafeNeighbours: true,
+			},
+			row:      5,
+			col:      5,
+			safeSize: 1,
+		},
+		{
+			name: "OK/SAFE_NEIGHBOURS_NOT_ENOUGH_ROOM",
+			game: model.Game{
+				Rows:           3,
+				Cols:           3,
+				Mines:          5,
+				FirstClickSafe: true,
+				SafeNeighbours: true,
+			},
+			row:      0,
+			col:      0,
+			safeSize: 0,
+		},
+	}
+
+	gameService := &GameService{
+		repo: nil,
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			newGame := gameService.StartGame(c.game)
+			assert.False(t, newGame.MinesPlaced)
+
+			gameService.PlaceMines(&newGame, c.row, c.col)
+			assert.True(t, newGame.MinesPlaced)
+
+			mines := 0
+			for x := 0; x < newGame.Rows; x++ {
+				for y := 0; y < newGame.Cols; y++ {
+					if newGame.Grid[x][y].Mine {
+						mines++
+					}
+					if x >= c.row-c.safeSize && x <= c.row+c.safeSize && y >= c.col-c.safeSize && y <= c.col+c.safeSize {
+						assert.False(t, newGame.Grid[x][y].Mine)
+					}
+				}
+			}
+			assert.Equal(t, c.game.Mines, mines)
+			if c.safeSize > 0 {
+				assert.Equal(t, uint8(0), newGame.Grid[c.row][c.col].MinesAround)
+			}
+		})
+	}
+}
+
+func TestGameServicePlaceMinesNoGuess(t *testing.T) {
+	cases := []struct {
+		name    string
+		game    model.Game
+		row     int
+		col     int
+		errText string
+	}{
+		{
+			name: "OK/INTERMEDIATE",
+			game: model.Game{
+				Rows:    16,
+				Cols:    16,
+				Mines:   40,
+				Seed:    1,
+				NoGuess: true,
+			},
+			row: 8,
+			col: 8,
+		},
+		{
+			name: "OK/EXPERT",
+			game: model.Game{
+				Rows:    16,
+				Cols:    30,
+				Mines:   99,
+				Seed:    1,
+				NoGuess: true,
+			},
+			row: 0,
+			col: 0,
+		},
+		{
+			name: "FAIL/ALWAYS_NEEDS_A_GUESS",
+			game: model.Game{
+				Rows:    2,
+				Cols:    6,
+				Mines:   3,
+				Seed:    1,
+				NoGuess: true,
+			},
+			row:     0,
+			col:     0,
+			errText: CantGenerateANoGuessBoard,
+		},
+	}
+
+	gameService := &GameService{
+		repo: nil,
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			newGame := gameService.StartGame(c.game)
+			assert.True(t, newGame.FirstClickSafe)
+
+			err := gameService.PlaceMines(&newGame, c.row, c.col)
+			if c.errText != "" {
+				assert.Equal(t, c.errText, err.Error())
+				assert.False(t, newGame.MinesPlaced)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.True(t, newGame.GenerationAttempts > 0)
+			assert.True(t, solver.Solvable(&newGame, c.row, c.col))
+		})
+	}
+}
+
+func TestGameServiceRestartGame(t *testing.T) {
+	cases := []struct {
+		name        string
+		game        model.Game
+		placeMines  bool
+		minesPlaced bool
+	}{
+		{
+			name: "OK/SEEDED_LAYOUT",
+			game: model.Game{
+				Rows:  10,
+				Cols:  10,
+				Mines: 20,
+			},
+			minesPlaced: true,
+		},
+		{
+			name: "OK/FIRST_CLICK_SAFE_LAYOUT",
+			game: model.Game{
+				Rows:           10,
+				Cols:           10,
+				Mines:          20,
+				FirstClickSafe: true,
+			},
+			placeMines:  true,
+			minesPlaced: true,
+		},
+		{
+			name: "OK/MINES_NOT_PLACED",
+			game: model.Game{
+				Rows:           10,
+				Cols:           10,
+				Mines:          20,
+				FirstClickSafe: true,
+			},
+			minesPlaced: false,
+		},
+	}
+
+	gameService := &GameService{
+		repo: nil,
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			source := gameService.StartGame(c.game)
+			source.ID = "7"
+			if c.placeMines {
+				gameService.PlaceMines(&source, 3, 3)
+			}
+			source.Grid[3][3].Revealed = true
+			source.CellsRevealed = 1
+			source.Status = model.Loose
+
+			newGame := gameService.RestartGame(&source)
+			assert.Empty(t, newGame.ID)
+			assert.Equal(t, "7", newGame.RestartedFrom)
+			assert.Equal(t, model.Running, newGame.Status)
+			assert.Equal(t, 0, newGame.CellsRevealed)
+			assert.Equal(t, source.Seed, newGame.Seed)
+			assert.Equal(t, c.minesPlaced, newGame.MinesPlaced)
+			for x := 0; x < newGame.Rows; x++ {
+				for y := 0; y < newGame.Cols; y++ {
+					assert.False(t, newGame.Grid[x][y].Revealed)
+					assert.Equal(t, source.Grid[x][y].Mine, newGame.Grid[x][y].Mine)
+					assert.Equal(t, source.Grid[x][y].MinesAround, newGame.Grid[x][y].MinesAround)
+				}
+			}
 		})
 	}
 }
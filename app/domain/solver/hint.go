@@ -0,0 +1,114 @@
+package solver
+
+import (
+	"fmt"
+
+	"github.com/egorkos/minesweeper/app/domain/model"
+)
+
+// Hint is a move suggested to the player. When no move can be deduced it
+// is the hidden cell least likely to be a mine, revealing it is a guess.
+type Hint struct {
+	Move
+	Certain     bool    `json:"certain"`
+	Probability float64 `json:"probability"`
+}
+
+// Hint returns the first move that can be deduced, or the hidden cell
+// least likely to be a mine when there is none. It tells if there was any
+// hidden cell left to hint.
+func (s *Solver) Hint() (Hint, bool) {
+	moves := s.Step()
+	if len(moves) > 0 {
+		hint := Hint{Move: moves[0], Certain: true}
+		if hint.Mine {
+			hint.Probability = 1
+		}
+		return hint, true
+	}
+
+	cell, probability, ok := s.leastLikelyMine()
+	if !ok {
+		return Hint{}, false
+	}
+
+	hint := Hint{
+		Move: Move{
+			Row:         cell.Row,
+			Col:         cell.Col,
+			Explanation: fmt.Sprintf("no cell can be deduced, (%d,%d) is the least likely to be a mine", cell.Row, cell.Col),
+		},
+		Probability: probability,
+	}
+	if probability == 0 {
+		hint.Certain = true
+		hint.Explanation = fmt.Sprintf("no layout matching the numbers and the mines left has a mine on (%d,%d)", cell.Row, cell.Col)
+	}
+	return hint, true
+}
+
+// leastLikelyMine returns the hidden cell with the lowest probability of
+// being a mine. When the frontier is too large to enumerate, the
+// probabilities are estimated.
+func (s *Solver) leastLikelyMine() (model.Coordinate, float64, bool) {
+	probabilities, err := s.Probabilities()
+	if err != nil {
+		probabilities = s.estimatedProbabilities()
+	}
+
+	var best model.Coordinate
+	lowest, found := 0.0, false
+	for x := 0; x < s.game.Rows; x++ {
+		for y := 0; y < s.game.Cols; y++ {
+			if !s.unknown(x, y) {
+				continue
+			}
+			if !found || probabilities[x][y] < lowest {
+				best, lowest, found = model.Coordinate{Row: x, Col: y}, probabilities[x][y], true
+			}
+		}
+	}
+	return best, lowest, found
+}
+
+// estimatedProbabilities estimates how likely each hidden cell is to be a
+// mine: cells next to numbers by the most demanding of them, the other
+// ones by the mines left to find.
+func (s *Solver) estimatedProbabilities() [][]float64 {
+	estimates := map[model.Coordinate]float64{}
+	for _, c := range s.constraints() {
+		p := float64(c.mines) / float64(len(c.cells))
+		for _, cl := range c.cells {
+			if estimate, exists := estimates[cl]; !exists || p > estimate {
+				estimates[cl] = p
+			}
+		}
+	}
+
+	unknown := 0
+	remaining := s.game.Mines
+	for x := 0; x < s.game.Rows; x++ {
+		for y := 0; y < s.game.Cols; y++ {
+			if s.mines[x][y] {
+				remaining--
+			} else if s.unknown(x, y) {
+				unknown++
+			}
+		}
+	}
+
+	probabilities := make([][]float64, s.game.Rows)
+	for x := range probabilities {
+		probabilities[x] = make([]float64, s.game.Cols)
+		for y := range probabilities[x] {
+			if s.mines[x][y] {
+				probabilities[x][y] = 1
+			} else if estimate, exists := estimates[model.Coordinate{Row: x, Col: y}]; exists {
+				probabilities[x][y] = estimate
+			} else if s.unknown(x, y) {
+				probabilities[x][y] = float64(remaining) / float64(unknown)
+			}
+		}
+	}
+	return probabilities
+}
@@ -0,0 +1,100 @@
+package solver
+
+import (
+	"testing"
+
+	"github.com/egorkos/minesweeper/app/domain/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSolverHint(t *testing.T) {
+	cases := []struct {
+		name     string
+		layout   []string
+		revealed []model.Coordinate
+		flagged  []model.Coordinate
+		ok       bool
+		expHint  Hint
+	}{
+		{
+			name:     "OK/SAFE_NEXT_TO_A_FLAG",
+			layout:   []string{".*.", "...", "..."},
+			revealed: []model.Coordinate{{Row: 0, Col: 0}},
+			flagged:  []model.Coordinate{{Row: 0, Col: 1}},
+			ok:       true,
+			expHint: Hint{
+				Move:    Move{Row: 1, Col: 0, Mine: false, Explanation: "the 1 at (0,0) already touches 1 flagged mine"},
+				Certain: true,
+			},
+		},
+		{
+			name:     "OK/MINE",
+			layout:   []string{"*.", ".."},
+			revealed: []model.Coordinate{{Row: 0, Col: 1}, {Row: 1, Col: 0}, {Row: 1, Col: 1}},
+			ok:       true,
+			expHint: Hint{
+				Move:        Move{Row: 0, Col: 0, Mine: true, Explanation: "the 1 at (0,1) only has 1 hidden cell left for its 1 missing mine"},
+				Certain:     true,
+				Probability: 1,
+			},
+		},
+		{
+			name:     "OK/SUBSET",
+			layout:   []string{"*.*", "...", "..."},
+			revealed: []model.Coordinate{{Row: 1, Col: 0}, {Row: 1, Col: 1}, {Row: 1, Col: 2}, {Row: 2, Col: 0}, {Row: 2, Col: 1}, {Row: 2, Col: 2}},
+			ok:       true,
+			expHint: Hint{
+				Move:        Move{Row: 0, Col: 2, Mine: true, Explanation: "the 2 at (1,1) needs 1 mine outside the cells it shares with the 1 at (1,0), and only has 1 hidden cell there"},
+				Certain:     true,
+				Probability: 1,
+			},
+		},
+		{
+			name:     "OK/SAFE_BY_PROBABILITY",
+			layout:   []string{".*..", "...."},
+			revealed: []model.Coordinate{{Row: 0, Col: 0}},
+			ok:       true,
+			expHint: Hint{
+				Move:        Move{Row: 0, Col: 2, Mine: false, Explanation: "no layout matching the numbers and the mines left has a mine on (0,2)"},
+				Certain:     true,
+				Probability: 0,
+			},
+		},
+		{
+			name:     "OK/LEAST_LIKELY_MINE",
+			layout:   []string{".*.*", "...."},
+			revealed: []model.Coordinate{{Row: 0, Col: 0}},
+			ok:       true,
+			expHint: Hint{
+				Move:        Move{Row: 0, Col: 2, Mine: false, Explanation: "no cell can be deduced, (0,2) is the least likely to be a mine"},
+				Certain:     false,
+				Probability: 0.25,
+			},
+		},
+		{
+			name:     "FAIL/NO_HIDDEN_CELL",
+			layout:   []string{"*."},
+			revealed: []model.Coordinate{{Row: 0, Col: 1}},
+			flagged:  []model.Coordinate{{Row: 0, Col: 0}},
+			ok:       false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			game := newGame(c.layout...)
+			for _, cl := range c.revealed {
+				game.Grid[cl.Row][cl.Col].Revealed = true
+			}
+			for _, cl := range c.flagged {
+				game.Grid[cl.Row][cl.Col].Mark = model.Flag
+			}
+
+			hint, ok := NewSolver(game).Hint()
+			assert.Equal(t, c.ok, ok)
+			if c.ok {
+				assert.Equal(t, c.expHint, hint)
+			}
+		})
+	}
+}
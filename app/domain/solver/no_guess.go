@@ -0,0 +1,72 @@
+package solver
+
+import (
+	"github.com/egorkos/minesweeper/app/domain/model"
+)
+
+// Solvable tells if a game with its mines already placed can be fully
+// solved by deduction, starting by revealing the given cell.
+func Solvable(game *model.Game, row, col int) bool {
+	shadow := hiddenCopy(game)
+	if shadow.Grid[row][col].Mine {
+		return false
+	}
+	reveal(&shadow, row, col)
+
+	s := NewSolver(&shadow)
+	for {
+		moves := s.Step()
+		if len(moves) == 0 {
+			break
+		}
+		for _, m := range moves {
+			if m.Mine {
+				s.MarkMine(m.Row, m.Col)
+				continue
+			}
+			if shadow.Grid[m.Row][m.Col].Mine {
+				return false
+			}
+			reveal(&shadow, m.Row, m.Col)
+			s.Revealed(m.Row, m.Col)
+		}
+	}
+
+	return shadow.CellsRevealed == shadow.Rows*shadow.Cols-shadow.Mines
+}
+
+// hiddenCopy returns a copy of the game with the same layout and every
+// cell hidden.
+func hiddenCopy(game *model.Game) model.Game {
+	shadow := *game
+	shadow.CellsRevealed = 0
+	shadow.Grid = model.NewGrid(game.Rows, game.Cols)
+	for x := range shadow.Grid {
+		for y := range shadow.Grid[x] {
+			shadow.Grid[x][y] = model.Cell{
+				Mine:        game.Grid[x][y].Mine,
+				MinesAround: game.Grid[x][y].MinesAround,
+			}
+		}
+	}
+	return shadow
+}
+
+// reveal opens a cell, cascading through cells without mines around.
+func reveal(game *model.Game, row, col int) {
+	pending := []model.Coordinate{{Row: row, Col: col}}
+	for len(pending) > 0 {
+		c := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+
+		if game.Grid[c.Row][c.Col].Revealed {
+			continue
+		}
+		game.Grid[c.Row][c.Col].Revealed = true
+		game.CellsRevealed++
+
+		if game.Grid[c.Row][c.Col].MinesAround == 0 {
+			pending = append(pending, game.Neighbours(c.Row, c.Col)...)
+		}
+	}
+}
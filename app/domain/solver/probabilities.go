@@ -0,0 +1,402 @@
+package solver
+
+import (
+	"errors"
+	"math"
+
+	"github.com/egorkos/minesweeper/app/domain/model"
+)
+
+// maxEnumerationSteps bounds the work spent enumerating the frontier, that
+// grows exponentially with its size.
+const maxEnumerationSteps = 1 << 22
+
+var (
+	ErrTooManyConfigurations = errors.New("too many undetermined cells to enumerate")
+	ErrInconsistentBoard     = errors.New("the flags don't match the revealed numbers")
+)
+
+// Probabilities returns the exact probability of each hidden cell being a
+// mine, counting every layout that matches the revealed numbers, the
+// flags and the total mines as equally likely. Revealed cells get 0 and
+// flagged ones 1.
+//
+// The hidden cells next to a revealed number, the frontier, are split in
+// groups that share no number, and the mine layouts of each group are
+// enumerated. The other hidden cells, the interior, can hold the remaining
+// mines in any way, so the layouts of the groups are weighted by how many
+// ways there are to place the mines they leave.
+func (s *Solver) Probabilities() ([][]float64, error) {
+	constraints := s.constraints()
+	for _, c := range constraints {
+		if c.mines < 0 || c.mines > len(c.cells) {
+			return nil, ErrInconsistentBoard
+		}
+	}
+
+	groups := frontierGroups(constraints)
+	steps := 0
+	for _, g := range groups {
+		if err := g.enumerate(&steps); err != nil {
+			return nil, err
+		}
+	}
+
+	remaining := s.game.Mines
+	interior := 0
+	inFrontier := map[model.Coordinate]bool{}
+	for _, g := range groups {
+		for _, cl := range g.cells {
+			inFrontier[cl] = true
+		}
+	}
+	for x := 0; x < s.game.Rows; x++ {
+		for y := 0; y < s.game.Cols; y++ {
+			if s.mines[x][y] {
+				remaining--
+			} else if s.unknown(x, y) && !inFrontier[model.Coordinate{Row: x, Col: y}] {
+				interior++
+			}
+		}
+	}
+
+	// before[i] is the fewest mines the groups before group i hold, and
+	// spans[i] how many more they may hold. Only those quantities are
+	// weighed, each one taking from the budget.
+	before := make([]int, len(groups)+1)
+	spans := make([]int, len(groups)+1)
+	for i, g := range groups {
+		before[i+1] = before[i] + g.fewest
+		spans[i+1] = spans[i] + len(g.layouts) - 1
+		steps += (spans[i] + 1) * len(g.layouts)
+	}
+	steps += spans[len(groups)] + 1
+	if steps > maxEnumerationSteps {
+		return nil, ErrTooManyConfigurations
+	}
+
+	// Layout counts grow exponentially with the board, so they are kept
+	// as logs: scaled counts would underflow on the mine quantities that
+	// actually happen when far larger ones never do.
+	//
+	// tails[i][m] weighs the layouts of the groups from i on, when
+	// before[i]+m mines are placed on the groups before, by the ways the
+	// interior cells can hold the mines left.
+	tails := make([][]float64, len(groups)+1)
+	tails[len(groups)] = make([]float64, spans[len(groups)]+1)
+	for m := range tails[len(groups)] {
+		tails[len(groups)][m] = logBinomial(interior, remaining-before[len(groups)]-m)
+	}
+	for i := len(groups) - 1; i >= 0; i-- {
+		tails[i] = make([]float64, spans[i]+1)
+		for m := range tails[i] {
+			tails[i][m] = math.Inf(-1)
+			for k, n := range groups[i].layouts {
+				tails[i][m] = logAdd(tails[i][m], n+tails[i+1][m+k])
+			}
+		}
+	}
+
+	probabilities := make([][]float64, s.game.Rows)
+	for x := range probabilities {
+		probabilities[x] = make([]float64, s.game.Cols)
+		for y := range probabilities[x] {
+			if s.mines[x][y] {
+				probabilities[x][y] = 1
+			}
+		}
+	}
+
+	// prefix counts the layouts of the groups before the current one by
+	// mines, from before[i] on.
+	prefix := []float64{0}
+	for i, g := range groups {
+		// weights[k] weighs the layouts of the group with fewest+k mines by
+		// the ways the rest of the board can hold the remaining ones
+		weights := make([]float64, len(g.layouts))
+		for k, n := range g.layouts {
+			weights[k] = math.Inf(-1)
+			for m, p := range prefix {
+				weights[k] = logAdd(weights[k], n+p+tails[i+1][m+k])
+			}
+		}
+		shares, ok := expShares(weights)
+		if !ok {
+			return nil, ErrInconsistentBoard
+		}
+		for c, cl := range g.cells {
+			mine := 0.0
+			for k, share := range shares {
+				mine += share * g.mineShares[c][k]
+			}
+			probabilities[cl.Row][cl.Col] = mine
+		}
+		prefix = convolve(prefix, g.layouts)
+	}
+
+	weights := make([]float64, len(prefix))
+	for k, p := range prefix {
+		weights[k] = p + tails[len(groups)][k]
+	}
+	shares, ok := expShares(weights)
+	if !ok {
+		return nil, ErrInconsistentBoard
+	}
+	if interior > 0 {
+		interiorMines := 0.0
+		for k, share := range shares {
+			interiorMines += share * float64(remaining-before[len(groups)]-k)
+		}
+		p := interiorMines / float64(interior)
+		for x := 0; x < s.game.Rows; x++ {
+			for y := 0; y < s.game.Cols; y++ {
+				if s.unknown(x, y) && !inFrontier[model.Coordinate{Row: x, Col: y}] {
+					probabilities[x][y] = p
+				}
+			}
+		}
+	}
+
+	return probabilities, nil
+}
+
+// group is a part of the frontier whose cells are linked by the numbers
+// they touch. Its layouts hold from fewest mines on: layouts[k] is the log
+// of how many of them hold fewest+k mines, and mineShares[c][k] the share
+// of those with a mine on its cell c.
+type group struct {
+	cells       []model.Coordinate
+	constraints []constraint
+	fewest      int
+	layouts     []float64
+	mineShares  [][]float64
+}
+
+func frontierGroups(constraints []constraint) []*group {
+	index := map[model.Coordinate]int{}
+	var cells []model.Coordinate
+	parent := []int{}
+	var find func(i int) int
+	find = func(i int) int {
+		for parent[i] != i {
+			parent[i] = parent[parent[i]]
+			i = parent[i]
+		}
+		return i
+	}
+	for _, c := range constraints {
+		for _, cl := range c.cells {
+			if _, exists := index[cl]; !exists {
+				index[cl] = len(cells)
+				cells = append(cells, cl)
+				parent = append(parent, len(parent))
+			}
+		}
+		first := find(index[c.cells[0]])
+		for _, cl := range c.cells[1:] {
+			parent[find(index[cl])] = first
+		}
+	}
+
+	byRoot := map[int]*group{}
+	var groups []*group
+	for i, cl := range cells {
+		root := find(i)
+		g, exists := byRoot[root]
+		if !exists {
+			g = &group{}
+			byRoot[root] = g
+			groups = append(groups, g)
+		}
+		g.cells = append(g.cells, cl)
+	}
+	for _, c := range constraints {
+		g := byRoot[find(index[c.cells[0]])]
+		g.constraints = append(g.constraints, c)
+	}
+	return groups
+}
+
+// enumerate counts the layouts of the group by backtracking over its
+// cells, dropping a branch as soon as a number can't be met anymore.
+func (g *group) enumerate(steps *int) error {
+	position := map[model.Coordinate]int{}
+	for i, cl := range g.cells {
+		position[cl] = i
+	}
+	touching := make([][]int, len(g.cells))
+	for j, c := range g.constraints {
+		for _, cl := range c.cells {
+			touching[position[cl]] = append(touching[position[cl]], j)
+		}
+	}
+
+	mines := make([]int, len(g.constraints))
+	left := make([]int, len(g.constraints))
+	for j, c := range g.constraints {
+		left[j] = len(c.cells)
+	}
+	// Every cell touches a number, so a layout holds at most the mines
+	// its numbers add up to
+	maxMines := 0
+	for _, c := range g.constraints {
+		maxMines += c.mines
+	}
+	if maxMines > len(g.cells) {
+		maxMines = len(g.cells)
+	}
+	// Each count is filled and turned into a share on its own, so the
+	// counts take from the budget before they are allocated
+	*steps += len(g.cells) * (maxMines + 1)
+	if *steps > maxEnumerationSteps {
+		return ErrTooManyConfigurations
+	}
+
+	layout := make([]bool, len(g.cells))
+	g.layouts = make([]float64, maxMines+1)
+	g.mineShares = make([][]float64, len(g.cells))
+	for c := range g.mineShares {
+		g.mineShares[c] = make([]float64, maxMines+1)
+	}
+
+	var place func(i, placed int) error
+	place = func(i, placed int) error {
+		*steps++
+		if *steps > maxEnumerationSteps {
+			return ErrTooManyConfigurations
+		}
+		if i == len(g.cells) {
+			g.layouts[placed]++
+			for c, mine := range layout {
+				if mine {
+					g.mineShares[c][placed]++
+				}
+			}
+			return nil
+		}
+
+		for _, mine := range []bool{false, true} {
+			ok := true
+			for _, j := range touching[i] {
+				left[j]--
+				if mine {
+					mines[j]++
+				}
+				if mines[j] > g.constraints[j].mines || mines[j]+left[j] < g.constraints[j].mines {
+					ok = false
+				}
+			}
+			layout[i] = mine
+			var err error
+			if ok {
+				added := 0
+				if mine {
+					added = 1
+				}
+				err = place(i+1, placed+added)
+			}
+			for _, j := range touching[i] {
+				left[j]++
+				if mine {
+					mines[j]--
+				}
+			}
+			if err != nil {
+				return err
+			}
+		}
+		layout[i] = false
+		return nil
+	}
+	if err := place(0, 0); err != nil {
+		return err
+	}
+
+	// Only the mine quantities some layout holds are kept
+	fewest, most := 0, len(g.layouts)-1
+	for fewest <= most && g.layouts[fewest] == 0 {
+		fewest++
+	}
+	for most >= fewest && g.layouts[most] == 0 {
+		most--
+	}
+	if fewest > most {
+		return ErrInconsistentBoard
+	}
+
+	g.fewest = fewest
+	g.layouts = g.layouts[fewest : most+1]
+	for c := range g.mineShares {
+		g.mineShares[c] = g.mineShares[c][fewest : most+1]
+	}
+	for k, n := range g.layouts {
+		// No layout may hold fewest+k mines, whose log is -Inf
+		g.layouts[k] = math.Log(n)
+		if n == 0 {
+			continue
+		}
+		for c := range g.mineShares {
+			g.mineShares[c][k] /= n
+		}
+	}
+	return nil
+}
+
+// convolve counts the layouts of two independent parts of the board by
+// mines, from the logs of the counts of each part.
+func convolve(a, b []float64) []float64 {
+	result := make([]float64, len(a)+len(b)-1)
+	for k := range result {
+		result[k] = math.Inf(-1)
+	}
+	for i := range a {
+		for j := range b {
+			result[i+j] = logAdd(result[i+j], a[i]+b[j])
+		}
+	}
+	return result
+}
+
+// expShares turns the logs of some weights into the share each one takes
+// of their sum. It fails when every weight is 0.
+func expShares(logs []float64) ([]float64, bool) {
+	largest := math.Inf(-1)
+	for _, l := range logs {
+		largest = math.Max(largest, l)
+	}
+	if math.IsInf(largest, -1) {
+		return nil, false
+	}
+
+	shares := make([]float64, len(logs))
+	total := 0.0
+	for k, l := range logs {
+		shares[k] = math.Exp(l - largest)
+		total += shares[k]
+	}
+	for k := range shares {
+		shares[k] /= total
+	}
+	return shares, true
+}
+
+// logAdd returns the log of e^a + e^b.
+func logAdd(a, b float64) float64 {
+	if a < b {
+		a, b = b, a
+	}
+	if math.IsInf(b, -1) {
+		return a
+	}
+	return a + math.Log1p(math.Exp(b-a))
+}
+
+func logBinomial(n, k int) float64 {
+	if k < 0 || k > n {
+		return math.Inf(-1)
+	}
+	a, _ := math.Lgamma(float64(n + 1))
+	b, _ := math.Lgamma(float64(k + 1))
+	c, _ := math.Lgamma(float64(n - k + 1))
+	return a - b - c
+}
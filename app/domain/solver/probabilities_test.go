@@ -0,0 +1,290 @@
+package solver
+
+import (
+	"math/rand"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/egorkos/minesweeper/app/domain/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSolverProbabilities(t *testing.T) {
+	cases := []struct {
+		name     string
+		layout   []string
+		revealed []model.Coordinate
+		flagged  []model.Coordinate
+		expected [][]float64
+		expError error
+	}{
+		{
+			name:     "OK/NOTHING_REVEALED",
+			layout:   []string{"*.", ".."},
+			expected: [][]float64{{0.25, 0.25}, {0.25, 0.25}},
+		},
+		{
+			name:     "OK/FIFTY_FIFTY",
+			layout:   []string{"..", "..", "*."},
+			revealed: []model.Coordinate{{Row: 0, Col: 0}, {Row: 0, Col: 1}, {Row: 1, Col: 0}, {Row: 1, Col: 1}},
+			expected: [][]float64{{0, 0}, {0, 0}, {0.5, 0.5}},
+		},
+		{
+			name:     "OK/INTERIOR",
+			layout:   []string{".*.*", "...."},
+			revealed: []model.Coordinate{{Row: 0, Col: 0}},
+			expected: [][]float64{{0, 1.0 / 3, 0.25, 0.25}, {1.0 / 3, 1.0 / 3, 0.25, 0.25}},
+		},
+		{
+			name:     "OK/FLAGGED",
+			layout:   []string{"*.", ".."},
+			revealed: []model.Coordinate{{Row: 0, Col: 1}},
+			flagged:  []model.Coordinate{{Row: 0, Col: 0}},
+			expected: [][]float64{{1, 0}, {0, 0}},
+		},
+		{
+			name:     "FAIL/WRONG_FLAG",
+			layout:   []string{"*..", "..."},
+			revealed: []model.Coordinate{{Row: 0, Col: 1}},
+			flagged:  []model.Coordinate{{Row: 1, Col: 1}, {Row: 1, Col: 2}},
+			expError: ErrInconsistentBoard,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			game := newGame(c.layout...)
+			for _, cl := range c.revealed {
+				game.Grid[cl.Row][cl.Col].Revealed = true
+			}
+			for _, cl := range c.flagged {
+				game.Grid[cl.Row][cl.Col].Mark = model.Flag
+			}
+
+			probabilities, err := NewSolver(game).Probabilities()
+			assert.Equal(t, c.expError, err)
+			if c.expError != nil {
+				return
+			}
+			for x := range c.expected {
+				assert.InDeltaSlice(t, c.expected[x], probabilities[x], 1e-9)
+			}
+		})
+	}
+}
+
+// TestSolverProbabilitiesLargeFrontier checks a frontier too large to
+// enumerate fails before its layout counts are allocated.
+func TestSolverProbabilitiesLargeFrontier(t *testing.T) {
+	const cols = 5000
+	mines := make([]byte, cols)
+	for y := range mines {
+		mines[y] = '.'
+		if y%2 == 0 {
+			mines[y] = '*'
+		}
+	}
+	game := newGame(strings.Repeat(".", cols), string(mines))
+	for y := 0; y < cols; y++ {
+		game.Grid[0][y].Revealed = true
+	}
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	_, err := NewSolver(game).Probabilities()
+	runtime.ReadMemStats(&after)
+
+	assert.Equal(t, ErrTooManyConfigurations, err)
+	assert.True(t, after.TotalAlloc-before.TotalAlloc < 64<<20)
+}
+
+// TestSolverProbabilitiesManyGroups checks boards with many frontier
+// groups, whose likely mine quantities are far less likely than the
+// others when taken alone.
+func TestSolverProbabilitiesManyGroups(t *testing.T) {
+	cases := []struct {
+		name  string
+		side  int
+		mines int
+	}{
+		{
+			name:  "OK/200x200",
+			side:  200,
+			mines: 4400,
+		},
+		{
+			name:  "OK/1000x1000",
+			side:  1000,
+			mines: 100000,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			game := &model.Game{
+				Rows:  c.side,
+				Cols:  c.side,
+				Mines: c.mines,
+				Grid:  model.NewGrid(c.side, c.side),
+			}
+			// A revealed 1 every 10 cells, each with its own group of 8
+			// hidden neighbours holding exactly 1 mine
+			ones := 0
+			for x := 5; x < c.side; x += 10 {
+				for y := 5; y < c.side; y += 10 {
+					game.Grid[x][y].Revealed = true
+					game.Grid[x][y].MinesAround = 1
+					ones++
+				}
+			}
+
+			probabilities, err := NewSolver(game).Probabilities()
+			assert.Nil(t, err)
+
+			interior := float64(c.mines-ones) / float64(c.side*c.side-9*ones)
+			assert.InDelta(t, 0, probabilities[5][5], 1e-9)
+			assert.InDelta(t, 1.0/8, probabilities[4][4], 1e-9)
+			assert.InDelta(t, 1.0/8, probabilities[6][5], 1e-9)
+			assert.InDelta(t, interior, probabilities[0][0], 1e-9)
+			assert.InDelta(t, interior, probabilities[10][10], 1e-9)
+		})
+	}
+}
+
+// TestSolverProbabilitiesManyWideGroups checks boards whose groups may
+// hold too many mine quantities together fail before weighing them.
+func TestSolverProbabilitiesManyWideGroups(t *testing.T) {
+	const side = 1000
+	game := &model.Game{
+		Rows:  side,
+		Cols:  side,
+		Mines: side * side / 10,
+		Grid:  model.NewGrid(side, side),
+	}
+	// Two revealed 1s side by side every 10 cells, each pair holding 1 or
+	// 2 mines
+	for x := 5; x < side; x += 10 {
+		for y := 5; y < side; y += 10 {
+			for _, col := range []int{y, y + 1} {
+				game.Grid[x][col].Revealed = true
+				game.Grid[x][col].MinesAround = 1
+			}
+		}
+	}
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	_, err := NewSolver(game).Probabilities()
+	runtime.ReadMemStats(&after)
+
+	assert.Equal(t, ErrTooManyConfigurations, err)
+	assert.True(t, after.TotalAlloc-before.TotalAlloc < 256<<20)
+}
+
+// TestSolverProbabilitiesBruteForce checks the probabilities on random
+// boards against counting every layout of the hidden cells.
+func TestSolverProbabilitiesBruteForce(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		game := randomGame(rnd, 4, 4, 1+rnd.Intn(5))
+		for x := range game.Grid {
+			for y := range game.Grid[x] {
+				if !game.Grid[x][y].Mine && rnd.Intn(3) == 0 {
+					game.Grid[x][y].Revealed = true
+				}
+			}
+		}
+
+		probabilities, err := NewSolver(game).Probabilities()
+		assert.Nil(t, err)
+		expected := bruteForceProbabilities(game)
+		for x := range expected {
+			assert.InDeltaSlice(t, expected[x], probabilities[x], 1e-9)
+		}
+	}
+}
+
+func randomGame(rnd *rand.Rand, rows, cols, mines int) *model.Game {
+	layout := make([][]byte, rows)
+	for x := range layout {
+		layout[x] = make([]byte, cols)
+		for y := range layout[x] {
+			layout[x][y] = '.'
+		}
+	}
+	for placed := 0; placed < mines; {
+		x, y := rnd.Intn(rows), rnd.Intn(cols)
+		if layout[x][y] != '*' {
+			layout[x][y] = '*'
+			placed++
+		}
+	}
+	lines := make([]string, rows)
+	for x := range layout {
+		lines[x] = string(layout[x])
+	}
+	return newGame(lines...)
+}
+
+func bruteForceProbabilities(game *model.Game) [][]float64 {
+	var hidden []model.Coordinate
+	for x := range game.Grid {
+		for y := range game.Grid[x] {
+			if !game.Grid[x][y].Revealed {
+				hidden = append(hidden, model.Coordinate{Row: x, Col: y})
+			}
+		}
+	}
+
+	total := 0.0
+	mines := make([]float64, len(hidden))
+	for mask := 0; mask < 1<<uint(len(hidden)); mask++ {
+		layout := map[model.Coordinate]bool{}
+		placed := 0
+		for i, cl := range hidden {
+			if mask&(1<<uint(i)) != 0 {
+				layout[cl] = true
+				placed++
+			}
+		}
+		if placed != game.Mines || !matches(game, layout) {
+			continue
+		}
+		total++
+		for i, cl := range hidden {
+			if layout[cl] {
+				mines[i]++
+			}
+		}
+	}
+
+	probabilities := make([][]float64, game.Rows)
+	for x := range probabilities {
+		probabilities[x] = make([]float64, game.Cols)
+	}
+	for i, cl := range hidden {
+		probabilities[cl.Row][cl.Col] = mines[i] / total
+	}
+	return probabilities
+}
+
+func matches(game *model.Game, layout map[model.Coordinate]bool) bool {
+	for x := range game.Grid {
+		for y := range game.Grid[x] {
+			if !game.Grid[x][y].Revealed {
+				continue
+			}
+			around := 0
+			for _, n := range game.Neighbours(x, y) {
+				if layout[n] {
+					around++
+				}
+			}
+			if around != int(game.Grid[x][y].MinesAround) {
+				return false
+			}
+		}
+	}
+	return true
+}
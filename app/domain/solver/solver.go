@@ -0,0 +1,288 @@
+package solver
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/egorkos/minesweeper/app/domain/model"
+)
+
+// Move is a cell whose content can be deduced with certainty, along with
+// how it was deduced.
+type Move struct {
+	Row         int    `json:"row"`
+	Col         int    `json:"col"`
+	Mine        bool   `json:"mine"`
+	Explanation string `json:"explanation"`
+}
+
+// constraint says that exactly mines of the cells are mines. It comes from
+// the number on a revealed cell, less the known mines around it.
+type constraint struct {
+	cells  []model.Coordinate
+	mines  int
+	cell   model.Coordinate
+	number int
+	known  int
+}
+
+// Solver deduces certain moves from what a player can see on a game:
+// the revealed numbers, the known mines and the total mines quantity.
+// Flagged cells are taken as known mines.
+//
+// A solver can follow a game as it is played, being told of the cells
+// revealed and the mines found, so it doesn't scan the whole board again
+// on every step.
+type Solver struct {
+	game  *model.Game
+	mines [][]bool
+	known int
+	// numbers holds the revealed numbers that may still have hidden cells
+	// around
+	numbers map[model.Coordinate]bool
+}
+
+func NewSolver(game *model.Game) *Solver {
+	s := &Solver{
+		game:    game,
+		mines:   make([][]bool, game.Rows),
+		numbers: map[model.Coordinate]bool{},
+	}
+	for x := range s.mines {
+		s.mines[x] = make([]bool, game.Cols)
+		for y := range s.mines[x] {
+			if game.Grid[x][y].Flagged() {
+				s.mines[x][y] = true
+				s.known++
+			} else if game.Grid[x][y].Revealed && !game.Grid[x][y].Mine {
+				s.numbers[model.Coordinate{Row: x, Col: y}] = true
+			}
+		}
+	}
+
+	return s
+}
+
+// Step returns every move that can be deduced right now, without
+// applying them.
+func (s *Solver) Step() []Move {
+	constraints := s.constraints()
+
+	moves := singleConstraintMoves(constraints)
+	if len(moves) == 0 {
+		moves = subsetMoves(constraints)
+	}
+	if len(moves) == 0 {
+		moves = s.mineCountMoves()
+	}
+
+	return moves
+}
+
+// MarkMine records a cell as a known mine, as if it was flagged.
+func (s *Solver) MarkMine(row, col int) {
+	if !s.mines[row][col] {
+		s.mines[row][col] = true
+		s.known++
+	}
+}
+
+// Revealed records the numbers a reveal on the cell opened, following the
+// cascade through the cells without mines around.
+func (s *Solver) Revealed(row, col int) {
+	visited := map[model.Coordinate]bool{}
+	pending := []model.Coordinate{{Row: row, Col: col}}
+	for len(pending) > 0 {
+		c := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+
+		cell := s.game.Grid[c.Row][c.Col]
+		if visited[c] || !cell.Revealed || cell.Mine {
+			continue
+		}
+		visited[c] = true
+		s.numbers[c] = true
+
+		if cell.MinesAround == 0 {
+			pending = append(pending, s.game.Neighbours(c.Row, c.Col)...)
+		}
+	}
+}
+
+func (s *Solver) unknown(row, col int) bool {
+	return !s.game.Grid[row][col].Revealed && !s.mines[row][col]
+}
+
+// constraints returns the constraints of the revealed numbers in row
+// major order, forgetting the numbers left without hidden cells around, as
+// hidden cells only ever get revealed or known as mines.
+func (s *Solver) constraints() []constraint {
+	cells := make([]model.Coordinate, 0, len(s.numbers))
+	for cl := range s.numbers {
+		cells = append(cells, cl)
+	}
+	sort.Slice(cells, func(i, j int) bool {
+		if cells[i].Row != cells[j].Row {
+			return cells[i].Row < cells[j].Row
+		}
+		return cells[i].Col < cells[j].Col
+	})
+
+	var constraints []constraint
+	for _, cl := range cells {
+		number := int(s.game.Grid[cl.Row][cl.Col].MinesAround)
+		c := constraint{
+			mines:  number,
+			cell:   cl,
+			number: number,
+		}
+		for _, n := range s.game.Neighbours(cl.Row, cl.Col) {
+			if s.mines[n.Row][n.Col] {
+				c.mines--
+				c.known++
+			} else if s.unknown(n.Row, n.Col) {
+				c.cells = append(c.cells, n)
+			}
+		}
+		if len(c.cells) > 0 {
+			constraints = append(constraints, c)
+		} else {
+			delete(s.numbers, cl)
+		}
+	}
+	return constraints
+}
+
+func singleConstraintMoves(constraints []constraint) []Move {
+	moves := newMoveSet()
+	for _, c := range constraints {
+		if c.mines == 0 && c.known == 0 {
+			moves.add(c.cells, false, fmt.Sprintf("%v has no mines around", c))
+		} else if c.mines == 0 {
+			moves.add(c.cells, false, fmt.Sprintf("%v already touches %s", c, count(c.known, "flagged mine")))
+		} else if c.mines == len(c.cells) {
+			moves.add(c.cells, true, fmt.Sprintf("%v only has %s left for its %s", c, count(len(c.cells), "hidden cell"), count(c.mines, "missing mine")))
+		}
+	}
+	return moves.list
+}
+
+// subsetMoves compares every pair of constraints where the cells of one
+// are contained in the other: the remaining cells hold the difference.
+func subsetMoves(constraints []constraint) []Move {
+	byCell := map[model.Coordinate][]int{}
+	for i, c := range constraints {
+		for _, cl := range c.cells {
+			byCell[cl] = append(byCell[cl], i)
+		}
+	}
+
+	moves := newMoveSet()
+	for i, a := range constraints {
+		compared := map[int]bool{i: true}
+		for _, cl := range a.cells {
+			for _, j := range byCell[cl] {
+				if compared[j] {
+					continue
+				}
+				compared[j] = true
+
+				b := constraints[j]
+				rest, ok := difference(b.cells, a.cells)
+				if !ok || len(rest) == 0 {
+					continue
+				}
+				mines := b.mines - a.mines
+				if mines == 0 {
+					moves.add(rest, false, fmt.Sprintf("%v gets all its missing mines from the cells it shares with %v", b, a))
+				} else if mines == len(rest) {
+					moves.add(rest, true, fmt.Sprintf("%v needs %s outside the cells it shares with %v, and only has %s there", b, count(mines, "mine"), a, count(len(rest), "hidden cell")))
+				}
+			}
+		}
+	}
+	return moves.list
+}
+
+func (s *Solver) mineCountMoves() []Move {
+	// The board is only scanned when the counts tell there are moves
+	remaining := s.game.Mines - s.known
+	hidden := s.game.Rows*s.game.Cols - s.game.CellsRevealed - s.known
+	if hidden <= 0 || (remaining != 0 && remaining != hidden) {
+		return nil
+	}
+
+	var unknown []model.Coordinate
+	for x := 0; x < s.game.Rows; x++ {
+		for y := 0; y < s.game.Cols; y++ {
+			if s.unknown(x, y) {
+				unknown = append(unknown, model.Coordinate{Row: x, Col: y})
+			}
+		}
+	}
+
+	moves := newMoveSet()
+	if len(unknown) == 0 {
+		return nil
+	}
+	if remaining == 0 {
+		moves.add(unknown, false, "every mine is already known")
+	} else if remaining == len(unknown) {
+		moves.add(unknown, true, fmt.Sprintf("the %s left hold the %s not known yet", count(len(unknown), "hidden cell"), count(remaining, "mine")))
+	}
+	return moves.list
+}
+
+// difference returns the cells of b that are not in a, and whether a is
+// a subset of b.
+func difference(b, a []model.Coordinate) ([]model.Coordinate, bool) {
+	inB := map[model.Coordinate]bool{}
+	for _, cl := range b {
+		inB[cl] = true
+	}
+	for _, cl := range a {
+		if !inB[cl] {
+			return nil, false
+		}
+		delete(inB, cl)
+	}
+
+	var rest []model.Coordinate
+	for _, cl := range b {
+		if inB[cl] {
+			rest = append(rest, cl)
+		}
+	}
+	return rest, true
+}
+
+type moveSet struct {
+	seen map[model.Coordinate]bool
+	list []Move
+}
+
+func newMoveSet() *moveSet {
+	return &moveSet{seen: map[model.Coordinate]bool{}}
+}
+
+func (m *moveSet) add(cells []model.Coordinate, mine bool, explanation string) {
+	for _, cl := range cells {
+		if m.seen[cl] {
+			continue
+		}
+		m.seen[cl] = true
+		m.list = append(m.list, Move{Row: cl.Row, Col: cl.Col, Mine: mine, Explanation: explanation})
+	}
+}
+
+// String names the number a constraint comes from, as in "the 1 at (3,4)".
+func (c constraint) String() string {
+	return fmt.Sprintf("the %d at (%d,%d)", c.number, c.cell.Row, c.cell.Col)
+}
+
+func count(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,149 @@
+package solver
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/egorkos/minesweeper/app/domain/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// newGame builds a game from a layout where '*' is a mine and '.' is an
+// empty cell.
+func newGame(layout ...string) *model.Game {
+	game := &model.Game{
+		Rows: len(layout),
+		Cols: len(layout[0]),
+		Grid: make([][]model.Cell, len(layout)),
+	}
+	for x, line := range layout {
+		game.Grid[x] = make([]model.Cell, len(line))
+		for y, c := range line {
+			if c == '*' {
+				game.Grid[x][y].Mine = true
+				game.Mines++
+			}
+		}
+	}
+	for x := range game.Grid {
+		for y := range game.Grid[x] {
+			if !game.Grid[x][y].Mine {
+				continue
+			}
+			for _, n := range game.Neighbours(x, y) {
+				game.Grid[n.Row][n.Col].MinesAround++
+			}
+		}
+	}
+	return game
+}
+
+func TestSolvable(t *testing.T) {
+	cases := []struct {
+		name     string
+		game     *model.Game
+		row      int
+		col      int
+		solvable bool
+	}{
+		{
+			name: "OK/SINGLE_CONSTRAINT",
+			game: newGame(
+				"...",
+				"...",
+				"..*",
+			),
+			row:      0,
+			col:      0,
+			solvable: true,
+		},
+		{
+			name: "OK/SUBSET",
+			game: newGame(
+				"....",
+				"....",
+				".**.",
+			),
+			row:      0,
+			col:      0,
+			solvable: true,
+		},
+		{
+			name: "OK/FIFTY_FIFTY",
+			game: newGame(
+				"..",
+				"..",
+				"*.",
+			),
+			row:      0,
+			col:      0,
+			solvable: false,
+		},
+		{
+			name: "OK/CLICK_ON_MINE",
+			game: newGame(
+				"*..",
+			),
+			row:      0,
+			col:      0,
+			solvable: false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.solvable, Solvable(c.game, c.row, c.col))
+		})
+	}
+}
+
+func TestSolverStep(t *testing.T) {
+	game := newGame(
+		".*.",
+		"...",
+		"...",
+	)
+	game.Grid[0][0].Revealed = true
+	game.Grid[0][1].Mark = model.Flag
+
+	moves := NewSolver(game).Step()
+	explanation := "the 1 at (0,0) already touches 1 flagged mine"
+	assert.Equal(t, []Move{
+		{Row: 1, Col: 0, Mine: false, Explanation: explanation},
+		{Row: 1, Col: 1, Mine: false, Explanation: explanation},
+	}, moves)
+}
+
+// TestSolverRevealed follows random games with one solver told of every
+// move, checking it deduces the same moves as a new solver on each step.
+func TestSolverRevealed(t *testing.T) {
+	rnd := rand.New(rand.NewSource(7))
+	for i := 0; i < 50; i++ {
+		game := randomGame(rnd, 16, 16, 30)
+		for x := range game.Grid {
+			for y := range game.Grid[x] {
+				if !game.Grid[x][y].Mine && game.CellsRevealed == 0 {
+					reveal(game, x, y)
+				}
+			}
+		}
+
+		s := NewSolver(game)
+		for {
+			moves := s.Step()
+			assert.Equal(t, NewSolver(game).Step(), moves)
+			if len(moves) == 0 {
+				break
+			}
+			for _, m := range moves {
+				if m.Mine {
+					game.Grid[m.Row][m.Col].Mark = model.Flag
+					s.MarkMine(m.Row, m.Col)
+					continue
+				}
+				reveal(game, m.Row, m.Col)
+				s.Revealed(m.Row, m.Col)
+			}
+		}
+	}
+}
@@ -3,6 +3,9 @@ package apierr
 type ApiError struct {
 	Message string `json:"message"`
 	Status  int    `json:"status"`
+	// Version is the current version of the game a conflicting change was
+	// rejected on
+	Version *int `json:"version,omitempty"`
 }
 
 func (e ApiError) Error() string {
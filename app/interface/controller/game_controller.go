@@ -1,17 +1,22 @@
 package controller
 
 import (
+	"io"
 	"net/http"
 	"strconv"
 
 	"github.com/egorkos/minesweeper/app/domain/model"
+	"github.com/egorkos/minesweeper/app/interface/view"
 	"github.com/egorkos/minesweeper/app/registry"
 	"github.com/egorkos/minesweeper/app/usecase"
 	"github.com/gin-gonic/gin"
 )
 
 const (
-	IdMustBeNumeric = "The ID must be numeric"
+	MovesMustBeNumeric  = "The moves to undo must be numeric"
+	StepMustBeNumeric   = "The replay step must be numeric"
+	OffsetMustBeNumeric = "The moves offset must be numeric"
+	LimitMustBeNumeric  = "The moves limit must be numeric"
 )
 
 type square struct {
@@ -39,19 +44,16 @@ func CreateGame(c *gin.Context) {
 	newGame, apiError := useCase.StartGame(newGame)
 
 	if apiError != nil {
-		c.AbortWithStatusJSON(apiError.Status, apiError.Error())
+		c.AbortWithStatusJSON(apiError.Status, view.NewErrorView(apiError))
 		return
 	}
 
-	c.JSON(http.StatusCreated, newGame)
+	c.JSON(http.StatusCreated, view.NewGameView(&newGame))
 	return
 }
 
 func GetGame(c *gin.Context) {
-	ID, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, IdMustBeNumeric)
-	}
+	ID := c.Param("id")
 
 	ctn := c.MustGet("ctn").(*registry.Container)
 	useCase := ctn.Resolve("game-usecase").(usecase.GameUsecase)
@@ -62,7 +64,7 @@ func GetGame(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, game)
+	c.JSON(http.StatusOK, view.NewGameView(game))
 	return
 }
 
@@ -72,22 +74,19 @@ func ListGames(c *gin.Context) {
 
 	games, apiError := useCase.FindAll()
 	if apiError != nil {
-		c.AbortWithStatusJSON(apiError.Status, apiError.Error())
+		c.AbortWithStatusJSON(apiError.Status, view.NewErrorView(apiError))
 		return
 	}
 
-	c.JSON(http.StatusOK, games)
+	c.JSON(http.StatusOK, view.NewGameViews(games))
 	return
 }
 
 func Reveal(c *gin.Context) {
-	ID, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, IdMustBeNumeric)
-	}
+	ID := c.Param("id")
 
 	var square square
-	err = c.BindJSON(&square)
+	err := c.BindJSON(&square)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
 		return
@@ -98,22 +97,42 @@ func Reveal(c *gin.Context) {
 
 	game, apiError := useCase.Reveal(ID, square.Row, square.Col)
 	if apiError != nil {
-		c.AbortWithStatusJSON(apiError.Status, apiError.Error())
+		c.AbortWithStatusJSON(apiError.Status, view.NewErrorView(apiError))
 		return
 	}
 
-	c.JSON(http.StatusOK, game)
+	c.JSON(http.StatusOK, view.NewGameView(game))
 	return
 }
 
 func Flag(c *gin.Context) {
-	ID, err := strconv.Atoi(c.Param("id"))
+	ID := c.Param("id")
+
+	var square square
+	err := c.BindJSON(&square)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, IdMustBeNumeric)
+		c.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
+		return
 	}
 
+	ctn := c.MustGet("ctn").(*registry.Container)
+	useCase := ctn.Resolve("game-usecase").(usecase.GameUsecase)
+
+	game, apiError := useCase.Flag(ID, square.Row, square.Col)
+	if apiError != nil {
+		c.AbortWithStatusJSON(apiError.Status, view.NewErrorView(apiError))
+		return
+	}
+
+	c.JSON(http.StatusOK, view.NewGameView(game))
+	return
+}
+
+func Chord(c *gin.Context) {
+	ID := c.Param("id")
+
 	var square square
-	err = c.BindJSON(&square)
+	err := c.BindJSON(&square)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
 		return
@@ -122,12 +141,247 @@ func Flag(c *gin.Context) {
 	ctn := c.MustGet("ctn").(*registry.Container)
 	useCase := ctn.Resolve("game-usecase").(usecase.GameUsecase)
 
-	game, apiError := useCase.Flag(ID, square.Row, square.Col)
+	game, apiError := useCase.Chord(ID, square.Row, square.Col)
+	if apiError != nil {
+		c.AbortWithStatusJSON(apiError.Status, view.NewErrorView(apiError))
+		return
+	}
+
+	c.JSON(http.StatusOK, view.NewGameView(game))
+	return
+}
+
+// GetFullGame returns the game without masking hidden cells, for admins.
+func GetFullGame(c *gin.Context) {
+	ID := c.Param("id")
+
+	ctn := c.MustGet("ctn").(*registry.Container)
+	useCase := ctn.Resolve("game-usecase").(usecase.GameUsecase)
+
+	game, apiError := useCase.FindByID(ID)
+	if apiError != nil {
+		c.String(apiError.Status, apiError.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, view.NewFullGameView(game))
+	return
+}
+
+func Pause(c *gin.Context) {
+	ID := c.Param("id")
+
+	ctn := c.MustGet("ctn").(*registry.Container)
+	useCase := ctn.Resolve("game-usecase").(usecase.GameUsecase)
+
+	game, apiError := useCase.Pause(ID)
+	if apiError != nil {
+		c.AbortWithStatusJSON(apiError.Status, view.NewErrorView(apiError))
+		return
+	}
+
+	c.JSON(http.StatusOK, view.NewGameView(game))
+	return
+}
+
+func Resume(c *gin.Context) {
+	ID := c.Param("id")
+
+	ctn := c.MustGet("ctn").(*registry.Container)
+	useCase := ctn.Resolve("game-usecase").(usecase.GameUsecase)
+
+	game, apiError := useCase.Resume(ID)
+	if apiError != nil {
+		c.AbortWithStatusJSON(apiError.Status, view.NewErrorView(apiError))
+		return
+	}
+
+	c.JSON(http.StatusOK, view.NewGameView(game))
+	return
+}
+
+func Resign(c *gin.Context) {
+	ID := c.Param("id")
+
+	ctn := c.MustGet("ctn").(*registry.Container)
+	useCase := ctn.Resolve("game-usecase").(usecase.GameUsecase)
+
+	game, apiError := useCase.Resign(ID)
+	if apiError != nil {
+		c.AbortWithStatusJSON(apiError.Status, view.NewErrorView(apiError))
+		return
+	}
+
+	c.JSON(http.StatusOK, view.NewGameView(game))
+	return
+}
+
+func Undo(c *gin.Context) {
+	ID := c.Param("id")
+
+	moves, err := strconv.Atoi(c.DefaultQuery("moves", "1"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, MovesMustBeNumeric)
+		return
+	}
+
+	ctn := c.MustGet("ctn").(*registry.Container)
+	useCase := ctn.Resolve("game-usecase").(usecase.GameUsecase)
+
+	game, apiError := useCase.Undo(ID, moves)
+	if apiError != nil {
+		c.AbortWithStatusJSON(apiError.Status, view.NewErrorView(apiError))
+		return
+	}
+
+	c.JSON(http.StatusOK, view.NewGameView(game))
+	return
+}
+
+func Restart(c *gin.Context) {
+	ID := c.Param("id")
+
+	ctn := c.MustGet("ctn").(*registry.Container)
+	useCase := ctn.Resolve("game-usecase").(usecase.GameUsecase)
+
+	game, apiError := useCase.Restart(ID)
+	if apiError != nil {
+		c.AbortWithStatusJSON(apiError.Status, view.NewErrorView(apiError))
+		return
+	}
+
+	c.JSON(http.StatusCreated, view.NewGameView(game))
+	return
+}
+
+func ListEvents(c *gin.Context) {
+	ID := c.Param("id")
+
+	ctn := c.MustGet("ctn").(*registry.Container)
+	useCase := ctn.Resolve("game-usecase").(usecase.GameUsecase)
+
+	events, apiError := useCase.Events(ID)
+	if apiError != nil {
+		c.AbortWithStatusJSON(apiError.Status, view.NewErrorView(apiError))
+		return
+	}
+
+	game, apiError := useCase.FindByID(ID)
+	if apiError != nil {
+		c.AbortWithStatusJSON(apiError.Status, view.NewErrorView(apiError))
+		return
+	}
+
+	c.JSON(http.StatusOK, view.NewEventViews(game, events))
+	return
+}
+
+// ListMoves returns a page of the game move history, oldest first.
+func ListMoves(c *gin.Context) {
+	ID := c.Param("id")
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, OffsetMustBeNumeric)
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, LimitMustBeNumeric)
+		return
+	}
+
+	ctn := c.MustGet("ctn").(*registry.Container)
+	useCase := ctn.Resolve("game-usecase").(usecase.GameUsecase)
+
+	moves, total, apiError := useCase.Moves(ID, offset, limit)
+	if apiError != nil {
+		c.AbortWithStatusJSON(apiError.Status, view.NewErrorView(apiError))
+		return
+	}
+
+	c.JSON(http.StatusOK, view.NewMovesView(offset, total, moves))
+	return
+}
+
+// Replay returns the game as it was after a step, or streams every step
+// as server-sent events when asked with stream=true.
+func Replay(c *gin.Context) {
+	ID := c.Param("id")
+
+	stream := c.Query("stream") == "true"
+	step, err := strconv.Atoi(c.DefaultQuery("step", "0"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, StepMustBeNumeric)
+		return
+	}
+
+	ctn := c.MustGet("ctn").(*registry.Container)
+	useCase := ctn.Resolve("game-usecase").(usecase.GameUsecase)
+
+	game, replay, apiError := useCase.Replay(ID, step)
+	if apiError != nil {
+		c.AbortWithStatusJSON(apiError.Status, view.NewErrorView(apiError))
+		return
+	}
+
+	if !stream {
+		c.JSON(http.StatusOK, view.NewReplayView(game, replay))
+		return
+	}
+
+	c.Stream(func(w io.Writer) bool {
+		c.SSEvent("step", view.NewReplayView(game, replay))
+		return replay.Next()
+	})
+	return
+}
+
+func Hint(c *gin.Context) {
+	ID := c.Param("id")
+
+	ctn := c.MustGet("ctn").(*registry.Container)
+	useCase := ctn.Resolve("game-usecase").(usecase.GameUsecase)
+
+	game, hint, apiError := useCase.Hint(ID)
+	if apiError != nil {
+		c.AbortWithStatusJSON(apiError.Status, view.NewErrorView(apiError))
+		return
+	}
+
+	c.JSON(http.StatusOK, view.NewHintView(game, hint))
+	return
+}
+
+func Probabilities(c *gin.Context) {
+	ID := c.Param("id")
+
+	ctn := c.MustGet("ctn").(*registry.Container)
+	useCase := ctn.Resolve("game-usecase").(usecase.GameUsecase)
+
+	game, probabilities, apiError := useCase.Probabilities(ID)
+	if apiError != nil {
+		c.AbortWithStatusJSON(apiError.Status, view.NewErrorView(apiError))
+		return
+	}
+
+	c.JSON(http.StatusOK, view.NewProbabilitiesView(game, probabilities))
+	return
+}
+
+func Autosolve(c *gin.Context) {
+	ID := c.Param("id")
+
+	ctn := c.MustGet("ctn").(*registry.Container)
+	useCase := ctn.Resolve("game-usecase").(usecase.GameUsecase)
+
+	game, moves, apiError := useCase.Autosolve(ID)
 	if apiError != nil {
-		c.AbortWithStatusJSON(apiError.Status, apiError.Error())
+		c.AbortWithStatusJSON(apiError.Status, view.NewErrorView(apiError))
 		return
 	}
 
-	c.JSON(http.StatusOK, game)
+	c.JSON(http.StatusOK, view.NewAutosolveView(game, moves))
 	return
 }
@@ -0,0 +1,53 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/egorkos/minesweeper/app/domain/model"
+	"github.com/egorkos/minesweeper/app/interface/view"
+	"github.com/egorkos/minesweeper/app/registry"
+	"github.com/egorkos/minesweeper/app/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func ListPresets(c *gin.Context) {
+	ctn := c.MustGet("ctn").(*registry.Container)
+	useCase := ctn.Resolve("preset-usecase").(usecase.PresetUsecase)
+
+	presets, apiError := useCase.FindAll()
+	if apiError != nil {
+		c.AbortWithStatusJSON(apiError.Status, view.NewErrorView(apiError))
+		return
+	}
+
+	c.JSON(http.StatusOK, presets)
+	return
+}
+
+func SavePreset(c *gin.Context) {
+	var preset model.Preset
+	err := c.BindJSON(&preset)
+
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = preset.Validate()
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctn := c.MustGet("ctn").(*registry.Container)
+	useCase := ctn.Resolve("preset-usecase").(usecase.PresetUsecase)
+	preset, apiError := useCase.Save(preset)
+
+	if apiError != nil {
+		c.AbortWithStatusJSON(apiError.Status, view.NewErrorView(apiError))
+		return
+	}
+
+	c.JSON(http.StatusCreated, preset)
+	return
+}
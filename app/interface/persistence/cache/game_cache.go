@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/egorkos/minesweeper/app/domain/model"
+)
+
+// GameCells bounds the cells of the games repositories keep in memory, 64MB
+// worth of cells.
+const GameCells = 16 << 20
+
+// GameCache keeps the games used last, until their cells add up to its
+// capacity, so a large board takes the room of many small ones. Games
+// larger than the whole capacity are never kept.
+type GameCache struct {
+	mux      *sync.Mutex
+	capacity int
+	cells    int
+	recent   *list.List
+	games    map[string]*list.Element
+}
+
+func NewGameCache(capacity int) *GameCache {
+	return &GameCache{
+		mux:      &sync.Mutex{},
+		capacity: capacity,
+		recent:   list.New(),
+		games:    map[string]*list.Element{},
+	}
+}
+
+// Get returns a kept game, that must not be changed.
+func (c *GameCache) Get(ID string) (*model.Game, bool) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	element, exists := c.games[ID]
+	if !exists {
+		return nil, false
+	}
+	c.recent.MoveToFront(element)
+	return element.Value.(*model.Game), true
+}
+
+// Put keeps a game, that must not be changed afterwards, dropping the
+// games used least recently when there is no room left.
+func (c *GameCache) Put(game *model.Game) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	c.remove(game.ID)
+	if cells(game) > c.capacity {
+		return
+	}
+
+	c.games[game.ID] = c.recent.PushFront(game)
+	c.cells += cells(game)
+	for c.cells > c.capacity {
+		c.remove(c.recent.Back().Value.(*model.Game).ID)
+	}
+}
+
+func (c *GameCache) remove(ID string) {
+	element, exists := c.games[ID]
+	if !exists {
+		return
+	}
+	c.recent.Remove(element)
+	delete(c.games, ID)
+	c.cells -= cells(element.Value.(*model.Game))
+}
+
+func cells(game *model.Game) int {
+	return game.Rows * game.Cols
+}
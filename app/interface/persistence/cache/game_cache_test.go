@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/egorkos/minesweeper/app/domain/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGameCache(t *testing.T) {
+	game := func(ID string, rows, cols int) *model.Game {
+		return &model.Game{ID: ID, Rows: rows, Cols: cols}
+	}
+	cases := []struct {
+		name  string
+		games []*model.Game
+		get   []string
+		kept  []string
+		gone  []string
+		cells int
+	}{
+		{
+			name:  "OK/KEEPS_WHAT_FITS",
+			games: []*model.Game{game("1", 2, 2), game("2", 2, 2)},
+			kept:  []string{"1", "2"},
+			cells: 8,
+		},
+		{
+			name:  "OK/DROPS_LEAST_RECENTLY_USED",
+			games: []*model.Game{game("1", 2, 2), game("2", 2, 2), game("3", 2, 2)},
+			kept:  []string{"2", "3"},
+			gone:  []string{"1"},
+			cells: 8,
+		},
+		{
+			name:  "OK/GET_KEEPS_A_GAME_RECENT",
+			games: []*model.Game{game("1", 2, 2), game("2", 2, 2)},
+			get:   []string{"1"},
+			kept:  []string{"1", "3"},
+			gone:  []string{"2"},
+			cells: 8,
+		},
+		{
+			name:  "OK/LARGE_BOARD_DROPS_MANY",
+			games: []*model.Game{game("1", 2, 2), game("2", 2, 2), game("3", 3, 3)},
+			kept:  []string{"3"},
+			gone:  []string{"1", "2"},
+			cells: 9,
+		},
+		{
+			name:  "OK/PUT_AGAIN_REPLACES",
+			games: []*model.Game{game("1", 2, 2), game("1", 1, 2)},
+			kept:  []string{"1"},
+			cells: 2,
+		},
+		{
+			name:  "OK/LARGER_THAN_CAPACITY_NOT_KEPT",
+			games: []*model.Game{game("1", 2, 2), game("1", 4, 4)},
+			gone:  []string{"1"},
+			cells: 0,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cache := NewGameCache(9)
+			for _, game := range c.games {
+				cache.Put(game)
+			}
+			for _, ID := range c.get {
+				_, exists := cache.Get(ID)
+				assert.True(t, exists)
+			}
+			if len(c.get) > 0 {
+				cache.Put(game("3", 2, 2))
+			}
+
+			for _, ID := range c.kept {
+				_, exists := cache.Get(ID)
+				assert.True(t, exists, ID)
+			}
+			for _, ID := range c.gone {
+				_, exists := cache.Get(ID)
+				assert.False(t, exists, ID)
+			}
+			assert.Equal(t, c.cells, cache.cells)
+		})
+	}
+}
@@ -0,0 +1,420 @@
+package file
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/egorkos/minesweeper/app/domain/model"
+	"github.com/egorkos/minesweeper/app/interface/apierr"
+	"github.com/egorkos/minesweeper/app/interface/persistence/cache"
+	"github.com/egorkos/minesweeper/app/interface/persistence/ids"
+	"github.com/egorkos/minesweeper/app/interface/persistence/locking"
+)
+
+const (
+	fileExtension = ".jsonl"
+	// legacyExtension is for the files holding the events as a JSON array,
+	// that are rewritten on their next save
+	legacyExtension = ".json"
+)
+
+// gameRepository stores the events of each game on a file named after the
+// game ID, one JSON event per line, so games outlive the process. New
+// events are appended to the file and synced. Games are only read when
+// first used and projected from their events, the ones used last are kept
+// in memory, copied in and out so callers never share them.
+type gameRepository struct {
+	*locking.GameRepository
+	dir   string
+	mux   *sync.RWMutex
+	files map[string]*gameFile
+	games *cache.GameCache
+}
+
+// gameFile is what is known of the file of a game. Until the file is read
+// only its path is known. It is never changed once kept, reading or saving
+// the game keeps a new one.
+type gameFile struct {
+	path   string
+	legacy bool
+	read   bool
+	// size is how much of the file holds whole events, a save that failed
+	// half way may have left more
+	size    int64
+	version int
+	status  model.GameStatus
+}
+
+// NewGameRepository lists the games stored on dir, creating it when
+// missing. New games get their IDs from the allocator.
+func NewGameRepository(dir string, allocator ids.Allocator) (*gameRepository, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	repo := &gameRepository{
+		dir:   dir,
+		mux:   &sync.RWMutex{},
+		files: map[string]*gameFile{},
+		games: cache.NewGameCache(cache.GameCells),
+	}
+	repo.GameRepository = locking.NewGameRepository(repo, allocator)
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || strings.HasPrefix(name, ".") {
+			continue
+		}
+		legacy := strings.HasSuffix(name, legacyExtension)
+		if !legacy && !strings.HasSuffix(name, fileExtension) {
+			continue
+		}
+		ID := strings.TrimSuffix(strings.TrimSuffix(name, fileExtension), legacyExtension)
+		if existing, exists := repo.files[ID]; exists && !existing.legacy {
+			// A rewrite stopped before removing the legacy file
+			continue
+		}
+
+		repo.files[ID] = &gameFile{path: filepath.Join(dir, name), legacy: legacy}
+		allocator.Reserve(ID)
+	}
+
+	return repo, nil
+}
+
+func (g *gameRepository) FindAll() ([]*model.Game, *apierr.ApiError) {
+	return g.find(func(*gameFile) bool { return true })
+}
+
+// FindUnfinished doesn't read again the games known to be finished.
+func (g *gameRepository) FindUnfinished() ([]*model.Game, *apierr.ApiError) {
+	games, err := g.find(func(file *gameFile) bool { return !file.read || !file.status.Finished() })
+	if err != nil {
+		return nil, err
+	}
+
+	unfinished := games[:0]
+	for _, game := range games {
+		if !game.Status.Finished() {
+			unfinished = append(unfinished, game)
+		}
+	}
+	return unfinished, nil
+}
+
+// find returns the games whose files may be kept, oldest first.
+func (g *gameRepository) find(keep func(*gameFile) bool) ([]*model.Game, *apierr.ApiError) {
+	g.mux.RLock()
+	var IDs []string
+	for ID, file := range g.files {
+		if keep(file) {
+			IDs = append(IDs, ID)
+		}
+	}
+	g.mux.RUnlock()
+
+	games := make([]*model.Game, 0, len(IDs))
+	for _, ID := range IDs {
+		game, err := g.FindByID(ID)
+		if err != nil {
+			return nil, err
+		}
+		games = append(games, game)
+	}
+	sort.Slice(games, func(i, j int) bool {
+		if !games[i].StartTime.Equal(games[j].StartTime) {
+			return games[i].StartTime.Before(games[j].StartTime)
+		}
+		return games[i].ID < games[j].ID
+	})
+	return games, nil
+}
+
+func (g *gameRepository) FindEvents(ID string) ([]model.Event, *apierr.ApiError) {
+	for {
+		file, err := g.findFile(ID)
+		if err != nil {
+			return nil, err
+		}
+
+		events, _, readErr := readEvents(file)
+		if os.IsNotExist(readErr) {
+			// The legacy file may have been rewritten in between
+			if current, err := g.findFile(ID); err == nil && current.path != file.path {
+				continue
+			}
+		}
+		if readErr != nil {
+			return nil, internalError(readErr)
+		}
+		return events, nil
+	}
+}
+
+// Load returns the kept game, projecting it from its file when it isn't
+// kept.
+func (g *gameRepository) Load(ID string) (*model.Game, *apierr.ApiError) {
+	if game, exists := g.games.Get(ID); exists {
+		return game.Copy(), nil
+	}
+
+	file, err := g.findFile(ID)
+	if err != nil {
+		return nil, err
+	}
+	_, game, err := g.read(ID, file)
+	if err != nil {
+		return nil, err
+	}
+	return game.Copy(), nil
+}
+
+func (g *gameRepository) Version(ID string) (int, *apierr.ApiError) {
+	file, err := g.findFile(ID)
+	if err != nil {
+		return 0, err
+	}
+	if !file.read {
+		file, _, err = g.read(ID, file)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return file.version, nil
+}
+
+// Save appends the new events to the game file and syncs it before keeping
+// them, so a failed write leaves the stored game as it was. New games are
+// written to a temporary file renamed in place, so a crash never leaves a
+// game file without its first events.
+func (g *gameRepository) Save(game *model.Game, events []model.Event) *apierr.ApiError {
+	file, err := g.findFile(game.ID)
+	var saved gameFile
+	var writeErr error
+	switch {
+	case err != nil:
+		saved = gameFile{path: g.path(game.ID)}
+		saved.size, writeErr = g.create(saved.path, events)
+	case file.legacy:
+		saved, writeErr = g.rewrite(game.ID, file, events)
+	default:
+		saved = *file
+		saved.size, writeErr = appendEvents(file, events)
+	}
+	if writeErr != nil {
+		return internalError(writeErr)
+	}
+
+	saved.read = true
+	saved.version += len(events)
+	saved.status = game.Status
+	g.mux.Lock()
+	g.files[game.ID] = &saved
+	g.mux.Unlock()
+	g.games.Put(game.Copy())
+
+	return nil
+}
+
+// findFile returns the file of a game, that may be unread.
+func (g *gameRepository) findFile(ID string) (*gameFile, *apierr.ApiError) {
+	g.mux.RLock()
+	defer g.mux.RUnlock()
+
+	file, exists := g.files[ID]
+	if !exists {
+		return nil, apierr.NewAPIError("Game Not Found", http.StatusNotFound)
+	}
+	return file, nil
+}
+
+// read projects the game on a file and keeps it, along with what is now
+// known of the file. It is only called holding the game lock.
+func (g *gameRepository) read(ID string, file *gameFile) (*gameFile, *model.Game, *apierr.ApiError) {
+	events, size, err := readEvents(file)
+	if err != nil {
+		return nil, nil, internalError(err)
+	}
+	game := model.Project(events)
+
+	read := *file
+	read.read = true
+	read.size = size
+	read.version = len(events)
+	read.status = game.Status
+	g.mux.Lock()
+	g.files[ID] = &read
+	g.mux.Unlock()
+	g.games.Put(game)
+
+	return &read, game, nil
+}
+
+// create writes the file of a new game atomically: the events go to a
+// temporary file that is synced and renamed to the game file.
+func (g *gameRepository) create(path string, events []model.Event) (int64, error) {
+	data, err := marshalEvents(events)
+	if err != nil {
+		return 0, err
+	}
+
+	tmp, err := ioutil.TempFile(g.dir, ".game-*.tmp")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return 0, err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return 0, err
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return 0, err
+	}
+
+	return int64(len(data)), syncDir(g.dir)
+}
+
+// rewrite moves the events of a legacy file, along with the new ones, to
+// a game file with an event per line.
+func (g *gameRepository) rewrite(ID string, legacy *gameFile, events []model.Event) (gameFile, error) {
+	file := *legacy
+	previous, _, err := readEvents(legacy)
+	if err != nil {
+		return file, err
+	}
+
+	file.path = g.path(ID)
+	file.legacy = false
+	file.size, err = g.create(file.path, append(previous, events...))
+	if err != nil {
+		return file, err
+	}
+	if err := os.Remove(legacy.path); err != nil {
+		return file, err
+	}
+
+	return file, syncDir(g.dir)
+}
+
+func (g *gameRepository) path(ID string) string {
+	return filepath.Join(g.dir, ID+fileExtension)
+}
+
+// appendEvents writes the events after the whole ones on the file, dropping
+// what a failed save left, and syncs it. It returns the new size.
+func appendEvents(file *gameFile, events []model.Event) (int64, error) {
+	data, err := marshalEvents(events)
+	if err != nil {
+		return 0, err
+	}
+
+	f, err := os.OpenFile(file.path, os.O_WRONLY, 0644)
+	if err != nil {
+		return 0, err
+	}
+	if err := f.Truncate(file.size); err != nil {
+		f.Close()
+		return 0, err
+	}
+	if _, err := f.WriteAt(data, file.size); err != nil {
+		f.Close()
+		return 0, err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return 0, err
+	}
+
+	return file.size + int64(len(data)), f.Close()
+}
+
+// readEvents reads the events on the file, along with the size of the
+// whole ones. Once a file was read only the events saved since are, what a
+// failed save left after them is not. A last line without its newline is
+// what a save interrupted by a crash left, and is dropped.
+func readEvents(file *gameFile) ([]model.Event, int64, error) {
+	data, err := ioutil.ReadFile(file.path)
+	if err != nil {
+		return nil, 0, err
+	}
+	if file.read && !file.legacy && int64(len(data)) > file.size {
+		data = data[:file.size]
+	}
+
+	var events []model.Event
+	if file.legacy {
+		if err := json.Unmarshal(data, &events); err != nil {
+			return nil, 0, fmt.Errorf("loading game %s: %v", file.path, err)
+		}
+		return events, int64(len(data)), nil
+	}
+
+	reader := bufio.NewReader(bytes.NewReader(data))
+	var size int64
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			return events, size, nil
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+
+		var event model.Event
+		if err := json.Unmarshal(line, &event); err != nil {
+			return nil, 0, fmt.Errorf("loading game %s: %v", file.path, err)
+		}
+		events = append(events, event)
+		size += int64(len(line))
+	}
+}
+
+func marshalEvents(events []model.Event) ([]byte, error) {
+	var data []byte
+	for _, event := range events {
+		line, err := json.Marshal(event)
+		if err != nil {
+			return nil, err
+		}
+		data = append(append(data, line...), '\n')
+	}
+	return data, nil
+}
+
+// syncDir makes a rename on the directory durable.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	return d.Sync()
+}
+
+func internalError(err error) *apierr.ApiError {
+	return apierr.NewAPIError(err.Error(), http.StatusInternalServerError)
+}
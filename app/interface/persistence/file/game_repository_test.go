@@ -0,0 +1,247 @@
+package file
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/egorkos/minesweeper/app/domain/model"
+	"github.com/egorkos/minesweeper/app/domain/repository"
+	"github.com/egorkos/minesweeper/app/interface/persistence/ids"
+	"github.com/egorkos/minesweeper/app/interface/persistence/repotest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGameRepository(t *testing.T) {
+	repotest.GameRepository(t, func(t *testing.T) repository.GameRepository {
+		repo, err := NewGameRepository(t.TempDir(), ids.NewSequentialAllocator())
+		assert.Nil(t, err)
+		return repo
+	})
+}
+
+func TestGameRepositoryReload(t *testing.T) {
+	now := time.Now()
+	created := model.Event{
+		Kind:     model.CreatedEvent,
+		Time:     now,
+		Settings: &model.GameSettings{Rows: 2, Cols: 2, Mines: 1},
+		Mines:    []model.Coordinate{{Row: 1, Col: 1}},
+	}
+	cases := []struct {
+		name   string
+		ID     string
+		events []model.Event
+		seqs   []int
+	}{
+		{
+			name:   "OK/SAVE",
+			ID:     "",
+			events: []model.Event{created},
+			seqs:   []int{1},
+		},
+		{
+			name: "OK/UPDATE",
+			ID:   "1",
+			events: []model.Event{
+				model.NewCellEvent(model.FlaggedEvent, 1, 1, now),
+				model.NewCellEvent(model.RevealedEvent, 0, 0, now),
+			},
+			seqs: []int{1, 2, 3},
+		},
+	}
+
+	dir := t.TempDir()
+	repo, err := NewGameRepository(dir, ids.NewSequentialAllocator())
+	assert.Nil(t, err)
+	game := &model.Game{}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.ID, game.ID)
+			for _, event := range c.events {
+				game.Apply(event)
+			}
+
+			err := repo.Append(game, c.events...)
+			assert.Nil(t, err)
+			assert.Equal(t, "1", game.ID)
+
+			reloaded, loadErr := NewGameRepository(dir, ids.NewSequentialAllocator())
+			assert.Nil(t, loadErr)
+
+			events, err := reloaded.FindEvents("1")
+			assert.Nil(t, err)
+			assert.Len(t, events, len(c.seqs))
+			for i, event := range events {
+				assert.Equal(t, "1", event.GameID)
+				assert.Equal(t, c.seqs[i], event.Seq)
+			}
+
+			saved, err := reloaded.FindByID("1")
+			assert.Nil(t, err)
+			assert.Equal(t, game.CellsRevealed, saved.CellsRevealed)
+			assert.Len(t, saved.Moves, len(game.Moves))
+			assert.True(t, game.StartTime.Equal(saved.StartTime))
+		})
+	}
+
+	t.Run("OK/NEXT_ID_AFTER_RELOAD", func(t *testing.T) {
+		reloaded, err := NewGameRepository(dir, ids.NewSequentialAllocator())
+		assert.Nil(t, err)
+
+		next := &model.Game{}
+		next.Apply(created)
+		assert.Nil(t, reloaded.Append(next, created))
+		assert.Equal(t, "2", next.ID)
+
+		games, findErr := reloaded.FindAll()
+		assert.Nil(t, findErr)
+		assert.Len(t, games, 2)
+	})
+
+	t.Run("OK/NO_TEMPORARY_FILES_LEFT", func(t *testing.T) {
+		files, err := ioutil.ReadDir(dir)
+		assert.Nil(t, err)
+		var names []string
+		for _, file := range files {
+			names = append(names, file.Name())
+		}
+		assert.Equal(t, []string{"1.jsonl", "2.jsonl"}, names)
+		_, err = ioutil.ReadFile(filepath.Join(dir, "1.jsonl"))
+		assert.Nil(t, err)
+	})
+}
+
+// numericIDGame is a game file as stored while game IDs were numbers.
+const numericIDGame = `[
+	{"game_id":3,"seq":1,"kind":"created","time":"2020-01-21T18:21:06Z","settings":{"rows":2,"cols":2,"mines":1,"wrap":false,"first_click_safe":false,"safe_neighbours":false,"no_guess":false,"practice":false,"restarted_from":1},"mines":[{"row":1,"col":1}]},
+	{"game_id":3,"seq":2,"kind":"revealed","time":"2020-01-21T18:21:10Z","cell":{"row":0,"col":0}}
+]`
+
+func TestGameRepositoryLoadNumericIDs(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "3.json"), []byte(numericIDGame), 0644))
+
+	repo, err := NewGameRepository(dir, ids.NewSequentialAllocator())
+	assert.Nil(t, err)
+
+	game, findErr := repo.FindByID("3")
+	assert.Nil(t, findErr)
+	assert.Equal(t, "3", game.ID)
+	assert.Equal(t, "1", game.RestartedFrom)
+	assert.Equal(t, 2, game.Version)
+	assert.True(t, game.Grid[0][0].Revealed)
+
+	events, findErr := repo.FindEvents("3")
+	assert.Nil(t, findErr)
+	for _, event := range events {
+		assert.Equal(t, "3", event.GameID)
+	}
+
+	t.Run("OK/NEXT_ID", func(t *testing.T) {
+		created := model.NewCreatedEvent(&model.Game{Rows: 2, Cols: 2, Mines: 1}, time.Now())
+		next := &model.Game{}
+		next.Apply(created)
+		assert.Nil(t, repo.Append(next, created))
+		assert.Equal(t, "4", next.ID)
+	})
+
+	t.Run("OK/REWRITTEN_WITH_STRING_IDS", func(t *testing.T) {
+		event := model.NewCellEvent(model.FlaggedEvent, 1, 1, time.Now())
+		event.Mark = model.Flag
+		game.Apply(event)
+		assert.Nil(t, repo.Append(game, event))
+
+		data, err := ioutil.ReadFile(filepath.Join(dir, "3.jsonl"))
+		assert.Nil(t, err)
+		_, err = os.Stat(filepath.Join(dir, "3.json"))
+		assert.True(t, os.IsNotExist(err))
+		assert.Contains(t, string(data), `"game_id":"3"`)
+		assert.Contains(t, string(data), `"restarted_from":"1"`)
+
+		reloaded, err := NewGameRepository(dir, ids.NewSequentialAllocator())
+		assert.Nil(t, err)
+		saved, findErr := reloaded.FindByID("3")
+		assert.Nil(t, findErr)
+		assert.Equal(t, 3, saved.Version)
+		assert.Equal(t, "1", saved.RestartedFrom)
+	})
+}
+
+func TestGameRepositoryAppend(t *testing.T) {
+	now := time.Now()
+	created := model.NewCreatedEvent(&model.Game{Rows: 2, Cols: 2, Mines: 1}, now)
+	flagged := model.NewCellEvent(model.FlaggedEvent, 1, 1, now)
+	flagged.Mark = model.Flag
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "1.jsonl")
+	repo, err := NewGameRepository(dir, ids.NewSequentialAllocator())
+	assert.Nil(t, err)
+	game := &model.Game{}
+	game.Apply(created)
+	assert.Nil(t, repo.Append(game, created))
+	first, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+
+	t.Run("OK/APPENDS_A_LINE", func(t *testing.T) {
+		game.Apply(flagged)
+		assert.Nil(t, repo.Append(game, flagged))
+
+		data, err := ioutil.ReadFile(path)
+		assert.Nil(t, err)
+		assert.Equal(t, string(first), string(data[:len(first)]))
+		assert.Equal(t, 2, strings.Count(string(data), "\n"))
+	})
+
+	t.Run("OK/DROPS_AN_INTERRUPTED_SAVE", func(t *testing.T) {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+		assert.Nil(t, err)
+		_, err = f.WriteString(`{"game_id":"1","seq":3,"kind":"rev`)
+		assert.Nil(t, err)
+		assert.Nil(t, f.Close())
+
+		reloaded, loadErr := NewGameRepository(dir, ids.NewSequentialAllocator())
+		assert.Nil(t, loadErr)
+		saved, findErr := reloaded.FindByID("1")
+		assert.Nil(t, findErr)
+		assert.Equal(t, 2, saved.Version)
+
+		revealed := model.NewCellEvent(model.RevealedEvent, 0, 0, now)
+		saved.Apply(revealed)
+		assert.Nil(t, reloaded.Append(saved, revealed))
+
+		events, findErr := reloaded.FindEvents("1")
+		assert.Nil(t, findErr)
+		assert.Len(t, events, 3)
+		data, err := ioutil.ReadFile(path)
+		assert.Nil(t, err)
+		assert.False(t, strings.Contains(string(data), `"kind":"rev"`))
+	})
+}
+
+func TestGameRepositoryLazyLoad(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "1.jsonl"), []byte("not an event\n"), 0644))
+
+	repo, err := NewGameRepository(dir, ids.NewSequentialAllocator())
+	assert.Nil(t, err)
+
+	_, findErr := repo.FindByID("1")
+	if assert.NotNil(t, findErr) {
+		assert.Equal(t, http.StatusInternalServerError, findErr.Status)
+	}
+
+	t.Run("OK/ID_RESERVED", func(t *testing.T) {
+		created := model.NewCreatedEvent(&model.Game{Rows: 2, Cols: 2, Mines: 1}, time.Now())
+		next := &model.Game{}
+		next.Apply(created)
+		assert.Nil(t, repo.Append(next, created))
+		assert.Equal(t, "2", next.ID)
+	})
+}
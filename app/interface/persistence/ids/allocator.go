@@ -0,0 +1,114 @@
+package ids
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	Sequential = "sequential"
+	ULID       = "ulid"
+)
+
+// Allocator hands out the IDs of new games.
+type Allocator interface {
+	// Next returns an ID that wasn't handed out nor reserved before.
+	Next() string
+	// Reserve keeps an ID already in use, e.g. loaded from storage, from
+	// being handed out.
+	Reserve(ID string)
+}
+
+// NewAllocator returns the allocator of the given kind.
+func NewAllocator(kind string) (Allocator, error) {
+	switch kind {
+	case Sequential:
+		return NewSequentialAllocator(), nil
+	case ULID:
+		return NewULIDAllocator(), nil
+	default:
+		return nil, fmt.Errorf("unknown game ID kind %q", kind)
+	}
+}
+
+// sequentialAllocator hands out increasing numbers, following the highest
+// one reserved.
+type sequentialAllocator struct {
+	mux  *sync.Mutex
+	last int
+}
+
+func NewSequentialAllocator() *sequentialAllocator {
+	return &sequentialAllocator{
+		mux: &sync.Mutex{},
+	}
+}
+
+func (s *sequentialAllocator) Next() string {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.last++
+	return strconv.Itoa(s.last)
+}
+
+func (s *sequentialAllocator) Reserve(ID string) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if n, err := strconv.Atoi(ID); err == nil && n > s.last {
+		s.last = n
+	}
+}
+
+// crockford is the base32 alphabet of ULIDs, without I, L, O and U so IDs
+// can't be misread.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ulidAllocator hands out ULIDs: 48 bits of milliseconds followed by 80
+// random bits, as 26 base32 characters. They sort by creation time and
+// can't be guessed from one another.
+type ulidAllocator struct {
+	now func() time.Time
+}
+
+func NewULIDAllocator() *ulidAllocator {
+	return &ulidAllocator{
+		now: time.Now,
+	}
+}
+
+func (u *ulidAllocator) Next() string {
+	var id [16]byte
+	ms := uint64(u.now().UnixNano() / int64(time.Millisecond))
+	binary.BigEndian.PutUint64(id[:8], ms<<16)
+	if _, err := rand.Read(id[6:]); err != nil {
+		panic(fmt.Sprintf("reading random bits: %v", err))
+	}
+
+	hi := binary.BigEndian.Uint64(id[:8])
+	lo := binary.BigEndian.Uint64(id[8:])
+	encoded := make([]byte, 26)
+	for i := range encoded {
+		// Each character takes 5 bits, the first one only gets 3
+		offset := uint(25-i) * 5
+		var bits uint64
+		switch {
+		case offset >= 64:
+			bits = hi >> (offset - 64)
+		case offset+5 <= 64:
+			bits = lo >> offset
+		default:
+			bits = lo>>offset | hi<<(64-offset)
+		}
+		encoded[i] = crockford[bits&31]
+	}
+	return string(encoded)
+}
+
+// Reserve does nothing, random IDs don't collide in practice.
+func (u *ulidAllocator) Reserve(ID string) {}
@@ -0,0 +1,59 @@
+package ids
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSequentialAllocator(t *testing.T) {
+	cases := []struct {
+		name     string
+		reserved []string
+		expected []string
+	}{
+		{
+			name:     "OK/EMPTY",
+			expected: []string{"1", "2", "3"},
+		},
+		{
+			name:     "OK/AFTER_RESERVED",
+			reserved: []string{"4", "2", "01ARZ3NDEKTSV4RRFFQ69G5FAV"},
+			expected: []string{"5", "6"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			allocator := NewSequentialAllocator()
+			for _, ID := range c.reserved {
+				allocator.Reserve(ID)
+			}
+
+			var allocated []string
+			for range c.expected {
+				allocated = append(allocated, allocator.Next())
+			}
+			assert.Equal(t, c.expected, allocated)
+		})
+	}
+}
+
+func TestULIDAllocator(t *testing.T) {
+	allocator := NewULIDAllocator()
+	// 1469918176385 milliseconds is 01ARYZ6S41 in the ULID spec
+	allocator.now = func() time.Time {
+		return time.Unix(0, 1469918176385*int64(time.Millisecond))
+	}
+
+	seen := map[string]bool{}
+	for i := 0; i < 1000; i++ {
+		ID := allocator.Next()
+		assert.Len(t, ID, 26)
+		assert.Equal(t, "01ARYZ6S41", ID[:10])
+		assert.Regexp(t, "^[0-9A-HJKMNP-TV-Z]+$", ID)
+		assert.False(t, seen[ID])
+		seen[ID] = true
+	}
+}
@@ -0,0 +1,172 @@
+package locking
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/egorkos/minesweeper/app/domain/model"
+	"github.com/egorkos/minesweeper/app/domain/repository"
+	"github.com/egorkos/minesweeper/app/interface/apierr"
+	"github.com/egorkos/minesweeper/app/interface/persistence/ids"
+)
+
+// GameStorage is where a repository keeps its games. Storages only read
+// and write, GameRepository takes care of the game locks.
+type GameStorage interface {
+	// Load returns a stored game, that the caller may change.
+	Load(ID string) (*model.Game, *apierr.ApiError)
+	// Version returns how many events are stored for a game.
+	Version(ID string) (int, *apierr.ApiError)
+	// Save stores the new events of a game, numbered and with its ID set,
+	// along with the game they lead to. The events of a new game start at
+	// seq 1. It is only called holding the game lock.
+	Save(game *model.Game, events []model.Event) *apierr.ApiError
+}
+
+// GameRepository stores games on a storage, serializing the changes of
+// each game with a lock of its own, so different games are played in
+// parallel. A lock is dropped once no one holds nor waits for it, so
+// looking up missing games leaves nothing behind.
+type GameRepository struct {
+	storage GameStorage
+	ids     ids.Allocator
+	mux     *sync.Mutex
+	locks   map[string]*gameLock
+}
+
+type gameLock struct {
+	sync.Mutex
+	users int
+}
+
+// NewGameRepository stores games on the storage, with IDs from the
+// allocator.
+func NewGameRepository(storage GameStorage, allocator ids.Allocator) *GameRepository {
+	return &GameRepository{
+		storage: storage,
+		ids:     allocator,
+		mux:     &sync.Mutex{},
+		locks:   map[string]*gameLock{},
+	}
+}
+
+func (g *GameRepository) FindByID(ID string) (*model.Game, *apierr.ApiError) {
+	g.lock(ID)
+	defer g.unlock(ID)
+
+	return g.storage.Load(ID)
+}
+
+// Append stores the events of a game, that must be built on the ones
+// already stored. New games get their ID here.
+func (g *GameRepository) Append(game *model.Game, events ...model.Event) *apierr.ApiError {
+	if game.ID == "" {
+		return g.create(game, events)
+	}
+
+	g.lock(game.ID)
+	defer g.unlock(game.ID)
+
+	stored, err := g.storage.Version(game.ID)
+	if err != nil {
+		return err
+	}
+	return g.save(game, events, stored)
+}
+
+// Update runs the update on the stored game while holding its lock, and
+// stores the events it returns. Nothing is stored when it fails.
+func (g *GameRepository) Update(ID string, update func(*model.Game) ([]model.Event, *apierr.ApiError)) (*model.Game, *apierr.ApiError) {
+	g.lock(ID)
+	defer g.unlock(ID)
+
+	game, err := g.storage.Load(ID)
+	if err != nil {
+		return nil, err
+	}
+	stored := game.Version
+
+	events, err := update(game)
+	if err != nil {
+		return nil, err
+	}
+
+	err = g.save(game, events, stored)
+	if err != nil {
+		return nil, err
+	}
+
+	return game, nil
+}
+
+// create stores a new game with the first allocated ID not in use.
+func (g *GameRepository) create(game *model.Game, events []model.Event) *apierr.ApiError {
+	for {
+		ID := g.ids.Next()
+		g.lock(ID)
+
+		_, err := g.storage.Version(ID)
+		if err == nil {
+			g.unlock(ID)
+			continue
+		}
+		if err.Status != http.StatusNotFound {
+			g.unlock(ID)
+			return err
+		}
+
+		game.ID = ID
+		err = g.save(game, events, 0)
+		g.unlock(ID)
+		if err != nil {
+			game.ID = ""
+		}
+		return err
+	}
+}
+
+// save numbers the events after the stored ones and saves them, when the
+// game was built on every stored event. The game must be locked.
+func (g *GameRepository) save(game *model.Game, events []model.Event, stored int) *apierr.ApiError {
+	if stored != game.Version-len(events) {
+		return repository.NewVersionConflict(stored)
+	}
+	if len(events) == 0 {
+		return nil
+	}
+
+	numbered := make([]model.Event, len(events))
+	for i, event := range events {
+		event.GameID = game.ID
+		event.Seq = stored + i + 1
+		numbered[i] = event
+	}
+
+	return g.storage.Save(game, numbered)
+}
+
+// lock takes the lock of a game, to be released with unlock.
+func (g *GameRepository) lock(ID string) {
+	g.mux.Lock()
+	lock, exists := g.locks[ID]
+	if !exists {
+		lock = &gameLock{}
+		g.locks[ID] = lock
+	}
+	lock.users++
+	g.mux.Unlock()
+
+	lock.Lock()
+}
+
+func (g *GameRepository) unlock(ID string) {
+	g.mux.Lock()
+	defer g.mux.Unlock()
+
+	lock := g.locks[ID]
+	lock.Unlock()
+	lock.users--
+	if lock.users == 0 {
+		delete(g.locks, ID)
+	}
+}
@@ -6,52 +6,117 @@ import (
 
 	"github.com/egorkos/minesweeper/app/domain/model"
 	"github.com/egorkos/minesweeper/app/interface/apierr"
+	"github.com/egorkos/minesweeper/app/interface/persistence/ids"
+	"github.com/egorkos/minesweeper/app/interface/persistence/locking"
 )
 
+// gameRepository keeps the events of each game, along with the game they
+// project to so it isn't rebuilt on every read. Games are copied in and
+// out, so callers never share the kept one.
 type gameRepository struct {
-	mux   *sync.Mutex
-	games map[int]*model.Game
+	*locking.GameRepository
+	mux   *sync.RWMutex
+	order []string
+	games map[string]*storedGame
 }
 
-func NewGameRepository() *gameRepository {
-	return &gameRepository{
-		mux:   &sync.Mutex{},
-		games: map[int]*model.Game{},
+// storedGame is never changed once kept, saving a game keeps a new one.
+type storedGame struct {
+	game   *model.Game
+	events []model.Event
+}
+
+// NewGameRepository keeps games with IDs from the given allocator.
+func NewGameRepository(allocator ids.Allocator) *gameRepository {
+	repo := &gameRepository{
+		mux:   &sync.RWMutex{},
+		games: map[string]*storedGame{},
 	}
+	repo.GameRepository = locking.NewGameRepository(repo, allocator)
+	return repo
 }
 
 func (g *gameRepository) FindAll() ([]*model.Game, *apierr.ApiError) {
-	g.mux.Lock()
-	defer g.mux.Unlock()
+	return g.find(func(*model.Game) bool { return true }), nil
+}
+
+func (g *gameRepository) FindUnfinished() ([]*model.Game, *apierr.ApiError) {
+	return g.find(func(game *model.Game) bool { return !game.Status.Finished() }), nil
+}
 
-	games := make([]*model.Game, len(g.games))
-	for i, game := range g.games {
-		games[i-1] = game
+// find returns copies of the kept games, in creation order.
+func (g *gameRepository) find(keep func(*model.Game) bool) []*model.Game {
+	g.mux.RLock()
+	var stored []*storedGame
+	for _, ID := range g.order {
+		if s := g.games[ID]; keep(s.game) {
+			stored = append(stored, s)
+		}
 	}
+	g.mux.RUnlock()
 
-	return games, nil
+	games := make([]*model.Game, len(stored))
+	for i, s := range stored {
+		games[i] = s.game.Copy()
+	}
+	return games
 }
 
-func (g *gameRepository) FindByID(id int) (*model.Game, *apierr.ApiError) {
-	g.mux.Lock()
-	defer g.mux.Unlock()
+func (g *gameRepository) FindEvents(ID string) ([]model.Event, *apierr.ApiError) {
+	stored, err := g.findStored(ID)
+	if err != nil {
+		return nil, err
+	}
 
-	game, exists := g.games[id]
-	if exists {
-		return game, nil
+	return append([]model.Event(nil), stored.events...), nil
+}
+
+func (g *gameRepository) Load(ID string) (*model.Game, *apierr.ApiError) {
+	stored, err := g.findStored(ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return stored.game.Copy(), nil
+}
+
+func (g *gameRepository) Version(ID string) (int, *apierr.ApiError) {
+	stored, err := g.findStored(ID)
+	if err != nil {
+		return 0, err
 	}
 
-	return nil, apierr.NewAPIError("Game Not Found", http.StatusNotFound)
+	return len(stored.events), nil
 }
 
-func (g *gameRepository) Upsert(game *model.Game) *apierr.ApiError {
+func (g *gameRepository) Save(game *model.Game, events []model.Event) *apierr.ApiError {
+	saved := game.Copy()
+
 	g.mux.Lock()
 	defer g.mux.Unlock()
 
-	if game.ID == 0 {
-		game.ID = len(g.games) + 1
+	stored, exists := g.games[game.ID]
+	if !exists {
+		stored = &storedGame{}
+		g.order = append(g.order, game.ID)
+	}
+	// Only the game lock holder appends, so the kept events are never
+	// overwritten
+	g.games[game.ID] = &storedGame{
+		game:   saved,
+		events: append(stored.events, events...),
 	}
-	g.games[game.ID] = game
 
 	return nil
 }
+
+func (g *gameRepository) findStored(ID string) (*storedGame, *apierr.ApiError) {
+	g.mux.RLock()
+	defer g.mux.RUnlock()
+
+	stored, exists := g.games[ID]
+	if !exists {
+		return nil, apierr.NewAPIError("Game Not Found", http.StatusNotFound)
+	}
+	return stored, nil
+}
@@ -3,30 +3,13 @@ package memory
 import (
 	"testing"
 
-	"github.com/egorkos/minesweeper/app/domain/model"
+	"github.com/egorkos/minesweeper/app/domain/repository"
+	"github.com/egorkos/minesweeper/app/interface/persistence/ids"
+	"github.com/egorkos/minesweeper/app/interface/persistence/repotest"
 )
 
-func TestGameRepositoryUpsert(t *testing.T) {
-	cases := []struct {
-		name string
-		game model.Game
-	}{
-		{
-			name: "OK/SAVE",
-			game: model.Game{ID: 0},
-		},
-		{
-			name: "OK/UPDATE",
-			game: model.Game{ID: 1, Rows: 10},
-		},
-	}
-
-	repo := NewGameRepository()
-
-	for _, c := range cases {
-		t.Run(c.name, func(t *testing.T) {
-			_ = repo.Upsert(&c.game)
-
-		})
-	}
+func TestGameRepository(t *testing.T) {
+	repotest.GameRepository(t, func(t *testing.T) repository.GameRepository {
+		return NewGameRepository(ids.NewSequentialAllocator())
+	})
 }
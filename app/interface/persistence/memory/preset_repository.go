@@ -0,0 +1,58 @@
+package memory
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/egorkos/minesweeper/app/domain/model"
+	"github.com/egorkos/minesweeper/app/interface/apierr"
+)
+
+type presetRepository struct {
+	mux     *sync.Mutex
+	presets map[string]*model.Preset
+}
+
+func NewPresetRepository() *presetRepository {
+	return &presetRepository{
+		mux:     &sync.Mutex{},
+		presets: map[string]*model.Preset{},
+	}
+}
+
+func (p *presetRepository) FindAll() ([]*model.Preset, *apierr.ApiError) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	presets := make([]*model.Preset, 0, len(p.presets))
+	for _, preset := range p.presets {
+		presets = append(presets, preset)
+	}
+	sort.Slice(presets, func(i, j int) bool {
+		return presets[i].Name < presets[j].Name
+	})
+
+	return presets, nil
+}
+
+func (p *presetRepository) FindByName(name string) (*model.Preset, *apierr.ApiError) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	preset, exists := p.presets[name]
+	if exists {
+		return preset, nil
+	}
+
+	return nil, apierr.NewAPIError("Preset Not Found", http.StatusNotFound)
+}
+
+func (p *presetRepository) Upsert(preset *model.Preset) *apierr.ApiError {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	p.presets[preset.Name] = preset
+
+	return nil
+}
@@ -0,0 +1,28 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/egorkos/minesweeper/app/domain/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPresetRepository(t *testing.T) {
+	repo := NewPresetRepository()
+
+	_ = repo.Upsert(&model.Preset{Name: "tiny", Rows: 5, Cols: 5, Mines: 3})
+	_ = repo.Upsert(&model.Preset{Name: "huge", Rows: 50, Cols: 50, Mines: 500})
+	_ = repo.Upsert(&model.Preset{Name: "tiny", Rows: 5, Cols: 5, Mines: 4})
+
+	presets, err := repo.FindAll()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(presets))
+	assert.Equal(t, "huge", presets[0].Name)
+
+	preset, err := repo.FindByName("tiny")
+	assert.Nil(t, err)
+	assert.Equal(t, 4, preset.Mines)
+
+	_, err = repo.FindByName("missing")
+	assert.Equal(t, "Preset Not Found", err.Error())
+}
@@ -0,0 +1,265 @@
+// Package repotest holds the tests every game repository must pass.
+package repotest
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/egorkos/minesweeper/app/domain/model"
+	"github.com/egorkos/minesweeper/app/domain/repository"
+	"github.com/egorkos/minesweeper/app/interface/apierr"
+	"github.com/stretchr/testify/assert"
+)
+
+// GameRepository runs the game repository tests on the repositories built
+// by newRepo, that must be empty and use sequential IDs.
+func GameRepository(t *testing.T, newRepo func(t *testing.T) repository.GameRepository) {
+	t.Run("Append", func(t *testing.T) {
+		testAppend(t, newRepo(t))
+	})
+	t.Run("Update", func(t *testing.T) {
+		testUpdate(t, newRepo(t))
+	})
+	t.Run("Create", func(t *testing.T) {
+		testCreate(t, newRepo(t))
+	})
+	t.Run("FindUnfinished", func(t *testing.T) {
+		testFindUnfinished(t, newRepo(t))
+	})
+}
+
+// created is the creation of a 2x2 game with a mine on its last cell.
+func created(now time.Time) model.Event {
+	return model.Event{
+		Kind:     model.CreatedEvent,
+		Time:     now,
+		Settings: &model.GameSettings{Rows: 2, Cols: 2, Mines: 1},
+		Mines:    []model.Coordinate{{Row: 1, Col: 1}},
+	}
+}
+
+func testAppend(t *testing.T, repo repository.GameRepository) {
+	// Stored times lose the monotonic clock
+	now := time.Now().Round(0).UTC()
+	cases := []struct {
+		name   string
+		ID     string
+		events []model.Event
+		seqs   []int
+	}{
+		{
+			name:   "OK/SAVE",
+			ID:     "",
+			events: []model.Event{created(now)},
+			seqs:   []int{1},
+		},
+		{
+			name: "OK/UPDATE",
+			ID:   "1",
+			events: []model.Event{
+				model.NewCellEvent(model.FlaggedEvent, 1, 1, now),
+				model.NewCellEvent(model.RevealedEvent, 0, 0, now),
+			},
+			seqs: []int{1, 2, 3},
+		},
+	}
+
+	game := &model.Game{}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.ID, game.ID)
+			for _, event := range c.events {
+				game.Apply(event)
+			}
+
+			err := repo.Append(game, c.events...)
+			assert.Nil(t, err)
+			assert.Equal(t, "1", game.ID)
+
+			events, err := repo.FindEvents("1")
+			assert.Nil(t, err)
+			assert.Len(t, events, len(c.seqs))
+			for i, event := range events {
+				assert.Equal(t, "1", event.GameID)
+				assert.Equal(t, c.seqs[i], event.Seq)
+			}
+
+			saved, err := repo.FindByID("1")
+			assert.Nil(t, err)
+			assert.Equal(t, model.Project(events), saved)
+			assert.Equal(t, game, saved)
+		})
+	}
+
+	t.Run("OK/COPIES", func(t *testing.T) {
+		saved, _ := repo.FindByID("1")
+		saved.Grid[1][1].Revealed = true
+
+		again, _ := repo.FindByID("1")
+		assert.False(t, again.Grid[1][1].Revealed)
+	})
+
+	t.Run("FAIL/STALE_VERSION", func(t *testing.T) {
+		stale, err := repo.FindByID("1")
+		assert.Nil(t, err)
+		current := stale.Version
+		event := model.NewCellEvent(model.FlaggedEvent, 1, 1, now)
+
+		winner, _ := repo.FindByID("1")
+		winner.Apply(event)
+		assert.Nil(t, repo.Append(winner, event))
+
+		stale.Apply(event)
+		err = repo.Append(stale, event)
+		assert.Equal(t, http.StatusConflict, err.Status)
+		assert.Equal(t, fmt.Sprintf(repository.GameVersionConflict, current+1), err.Message)
+		if assert.NotNil(t, err.Version) {
+			assert.Equal(t, current+1, *err.Version)
+		}
+
+		events, _ := repo.FindEvents("1")
+		assert.Len(t, events, current+1)
+	})
+
+	t.Run("FAIL/NOT_FOUND", func(t *testing.T) {
+		_, err := repo.FindByID("2")
+		assert.Equal(t, http.StatusNotFound, err.Status)
+		_, err = repo.FindEvents("2")
+		assert.Equal(t, http.StatusNotFound, err.Status)
+
+		missing := &model.Game{}
+		event := created(now)
+		missing.Apply(event)
+		missing.ID = "2"
+		err = repo.Append(missing, event)
+		assert.Equal(t, http.StatusNotFound, err.Status)
+	})
+}
+
+func testUpdate(t *testing.T, repo repository.GameRepository) {
+	now := time.Now().Round(0).UTC()
+	game := &model.Game{}
+	game.Apply(created(now))
+	assert.Nil(t, repo.Append(game, created(now)))
+
+	t.Run("OK/CONCURRENT_UPDATES", func(t *testing.T) {
+		const updates = 20
+		var wg sync.WaitGroup
+		for i := 0; i < updates; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := repo.Update("1", func(game *model.Game) ([]model.Event, *apierr.ApiError) {
+					event := model.NewCellEvent(model.FlaggedEvent, 0, 0, now)
+					event.Mark = model.NextMark(nil, game.Grid[0][0].Mark)
+					game.Apply(event)
+					return []model.Event{event}, nil
+				})
+				assert.Nil(t, err)
+			}()
+		}
+		wg.Wait()
+
+		saved, err := repo.FindByID("1")
+		assert.Nil(t, err)
+		assert.Equal(t, updates+1, saved.Version)
+		assert.Len(t, saved.Moves, updates)
+		assert.Equal(t, model.NoMark, saved.Grid[0][0].Mark)
+	})
+
+	t.Run("FAIL/NOTHING_STORED", func(t *testing.T) {
+		updated, err := repo.Update("1", func(game *model.Game) ([]model.Event, *apierr.ApiError) {
+			game.Apply(model.NewCellEvent(model.RevealedEvent, 1, 1, now))
+			return nil, apierr.NewAPIError("rejected", http.StatusBadRequest)
+		})
+		assert.Nil(t, updated)
+		assert.Equal(t, "rejected", err.Error())
+
+		saved, _ := repo.FindByID("1")
+		assert.False(t, saved.Grid[1][1].Revealed)
+		events, _ := repo.FindEvents("1")
+		assert.Len(t, events, saved.Version)
+	})
+
+	t.Run("FAIL/NOT_FOUND", func(t *testing.T) {
+		_, err := repo.Update("2", func(game *model.Game) ([]model.Event, *apierr.ApiError) {
+			return nil, nil
+		})
+		assert.Equal(t, http.StatusNotFound, err.Status)
+	})
+}
+
+func testCreate(t *testing.T, repo repository.GameRepository) {
+	now := time.Now().Round(0).UTC()
+
+	t.Run("OK/CONCURRENT_CREATES", func(t *testing.T) {
+		const creates = 20
+		IDs := make([]string, creates)
+		var wg sync.WaitGroup
+		for i := 0; i < creates; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				game := &model.Game{}
+				game.Apply(created(now))
+				assert.Nil(t, repo.Append(game, created(now)))
+				IDs[i] = game.ID
+			}(i)
+		}
+		wg.Wait()
+
+		unique := map[string]bool{}
+		for _, ID := range IDs {
+			assert.NotEmpty(t, ID)
+			unique[ID] = true
+		}
+		assert.Len(t, unique, creates)
+
+		games, err := repo.FindAll()
+		assert.Nil(t, err)
+		assert.Len(t, games, creates)
+	})
+
+	t.Run("FAIL/STALE_NEW_GAME", func(t *testing.T) {
+		game := &model.Game{}
+		game.Apply(created(now))
+		err := repo.Append(game)
+		assert.Equal(t, http.StatusConflict, err.Status)
+		assert.Empty(t, game.ID)
+	})
+}
+
+func testFindUnfinished(t *testing.T, repo repository.GameRepository) {
+	now := time.Now().Round(0).UTC()
+	endings := []*model.Event{
+		nil,
+		{Kind: model.PausedEvent, Time: now},
+		{Kind: model.FinishedEvent, Time: now, Reason: model.PlayerResigned},
+	}
+	for _, ending := range endings {
+		game := &model.Game{}
+		events := []model.Event{created(now)}
+		if ending != nil {
+			events = append(events, *ending)
+		}
+		for _, event := range events {
+			game.Apply(event)
+		}
+		assert.Nil(t, repo.Append(game, events...))
+	}
+
+	games, err := repo.FindUnfinished()
+	assert.Nil(t, err)
+	assert.Len(t, games, 2)
+	for _, game := range games {
+		assert.False(t, game.Status.Finished())
+	}
+
+	games, err = repo.FindAll()
+	assert.Nil(t, err)
+	assert.Len(t, games, 3)
+}
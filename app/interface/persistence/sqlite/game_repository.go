@@ -0,0 +1,290 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/egorkos/minesweeper/app/domain/model"
+	"github.com/egorkos/minesweeper/app/interface/apierr"
+	"github.com/egorkos/minesweeper/app/interface/persistence/cache"
+	"github.com/egorkos/minesweeper/app/interface/persistence/ids"
+	"github.com/egorkos/minesweeper/app/interface/persistence/locking"
+
+	// Pure Go SQLite driver, registered as "sqlite"
+	_ "modernc.org/sqlite"
+)
+
+// timeFormat keeps every stored time in UTC with the same width, so they
+// sort as text.
+const timeFormat = "2006-01-02T15:04:05.000000000Z"
+
+// The game listings, each one on its own index.
+const (
+	findAllQuery        = "SELECT id FROM games ORDER BY start_time, rowid"
+	findUnfinishedQuery = "SELECT id FROM games WHERE status IN (?, ?) ORDER BY rowid"
+)
+
+// gameRepository stores the events of each game on a SQLite database, next
+// to a row per game with the fields games are searched by. Games are
+// projected from their events on read, the ones used last are kept in
+// memory, copied in and out so callers never share them.
+type gameRepository struct {
+	*locking.GameRepository
+	db    *sql.DB
+	games *cache.GameCache
+}
+
+// NewGameRepository opens the database on path, creating it when missing,
+// and brings its schema up to date. New games get their IDs from the
+// allocator.
+func NewGameRepository(path string, allocator ids.Allocator) (*gameRepository, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, err
+	}
+	// A single connection serializes the writes, SQLite only takes one at
+	// a time anyway
+	db.SetMaxOpenConns(1)
+
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	repo := &gameRepository{
+		db:    db,
+		games: cache.NewGameCache(cache.GameCells),
+	}
+	repo.GameRepository = locking.NewGameRepository(repo, allocator)
+	IDs, err := repo.findIDs("SELECT id FROM games")
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	for _, ID := range IDs {
+		allocator.Reserve(ID)
+	}
+
+	return repo, nil
+}
+
+func (g *gameRepository) Close() error {
+	return g.db.Close()
+}
+
+func (g *gameRepository) FindAll() ([]*model.Game, *apierr.ApiError) {
+	return g.findGames(findAllQuery)
+}
+
+// FindUnfinished never reads finished games.
+func (g *gameRepository) FindUnfinished() ([]*model.Game, *apierr.ApiError) {
+	return g.findGames(findUnfinishedQuery, int(model.Running), int(model.Paused))
+}
+
+// findGames returns the games whose IDs the query selects.
+func (g *gameRepository) findGames(query string, args ...interface{}) ([]*model.Game, *apierr.ApiError) {
+	IDs, err := g.findIDs(query, args...)
+	if err != nil {
+		return nil, internalError(err)
+	}
+
+	games := make([]*model.Game, 0, len(IDs))
+	for _, ID := range IDs {
+		game, apiErr := g.FindByID(ID)
+		if apiErr != nil {
+			return nil, apiErr
+		}
+		games = append(games, game)
+	}
+
+	return games, nil
+}
+
+func (g *gameRepository) FindEvents(ID string) ([]model.Event, *apierr.ApiError) {
+	return g.findEvents(ID)
+}
+
+// Load returns the kept game, projecting it from its stored events when
+// it isn't kept.
+func (g *gameRepository) Load(ID string) (*model.Game, *apierr.ApiError) {
+	if game, exists := g.games.Get(ID); exists {
+		return game.Copy(), nil
+	}
+
+	events, apiErr := g.findEvents(ID)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	game := model.Project(events)
+	g.games.Put(game)
+	return game.Copy(), nil
+}
+
+func (g *gameRepository) Version(ID string) (int, *apierr.ApiError) {
+	var seq sql.NullInt64
+	err := g.db.QueryRow("SELECT MAX(seq) FROM events WHERE game_id = ?", ID).Scan(&seq)
+	if err != nil {
+		return 0, internalError(err)
+	}
+	if !seq.Valid {
+		return 0, apierr.NewAPIError("Game Not Found", http.StatusNotFound)
+	}
+	return int(seq.Int64), nil
+}
+
+// Save stores the events and the game row in a single transaction, and
+// only keeps the game once it is committed.
+func (g *gameRepository) Save(game *model.Game, events []model.Event) *apierr.ApiError {
+	tx, err := g.db.Begin()
+	if err != nil {
+		return internalError(err)
+	}
+
+	apiErr := saveGame(tx, game, events[0].Seq == 1)
+	if apiErr != nil {
+		tx.Rollback()
+		return apiErr
+	}
+
+	for _, event := range events {
+		data, err := json.Marshal(event)
+		if err != nil {
+			tx.Rollback()
+			return internalError(err)
+		}
+		_, err = tx.Exec("INSERT INTO events (game_id, seq, kind, time, data) VALUES (?, ?, ?, ?, ?)",
+			game.ID, event.Seq, string(event.Kind), timestamp(event.Time), string(data))
+		if err != nil {
+			tx.Rollback()
+			return internalError(err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return internalError(err)
+	}
+
+	g.games.Put(game.Copy())
+
+	return nil
+}
+
+// saveGame inserts the row of a new game or updates the one of a stored
+// game.
+func saveGame(tx *sql.Tx, game *model.Game, created bool) *apierr.ApiError {
+	values := []interface{}{
+		int(game.Status),
+		nullable(string(game.FinishReason)),
+		game.Rows,
+		game.Cols,
+		game.Mines,
+		nullable(game.Preset),
+		game.Practice,
+		timestamp(game.StartTime),
+		timestamp(game.FinishTime),
+		timestamp(game.LastMoveTime),
+		game.ID,
+	}
+
+	if created {
+		_, err := tx.Exec(`INSERT INTO games
+			(status, finish_reason, board_rows, board_cols, mines, preset, practice, start_time, finish_time, last_move_time, id)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, values...)
+		if err != nil {
+			return internalError(err)
+		}
+		return nil
+	}
+
+	result, err := tx.Exec(`UPDATE games SET
+		status = ?, finish_reason = ?, board_rows = ?, board_cols = ?, mines = ?, preset = ?, practice = ?,
+		start_time = ?, finish_time = ?, last_move_time = ?
+		WHERE id = ?`, values...)
+	if err != nil {
+		return internalError(err)
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return internalError(err)
+	}
+	if updated == 0 {
+		return apierr.NewAPIError("Game Not Found", http.StatusNotFound)
+	}
+	return nil
+}
+
+// findIDs returns the game IDs the query selects.
+func (g *gameRepository) findIDs(query string, args ...interface{}) ([]string, error) {
+	rows, err := g.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var IDs []string
+	for rows.Next() {
+		var ID string
+		if err := rows.Scan(&ID); err != nil {
+			return nil, err
+		}
+		IDs = append(IDs, ID)
+	}
+	return IDs, rows.Err()
+}
+
+func (g *gameRepository) findEvents(ID string) ([]model.Event, *apierr.ApiError) {
+	rows, err := g.db.Query("SELECT data FROM events WHERE game_id = ? ORDER BY seq", ID)
+	if err != nil {
+		return nil, internalError(err)
+	}
+	defer rows.Close()
+
+	var events []model.Event
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, internalError(err)
+		}
+		var event model.Event
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return nil, internalError(err)
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, internalError(err)
+	}
+
+	if len(events) == 0 {
+		return nil, apierr.NewAPIError("Game Not Found", http.StatusNotFound)
+	}
+	return events, nil
+}
+
+// timestamp formats a time for storage, zero times are stored as NULL.
+func timestamp(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UTC().Format(timeFormat)
+}
+
+func nullable(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+func internalError(err error) *apierr.ApiError {
+	return apierr.NewAPIError(err.Error(), http.StatusInternalServerError)
+}
@@ -0,0 +1,194 @@
+package sqlite
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/egorkos/minesweeper/app/domain/model"
+	"github.com/egorkos/minesweeper/app/domain/repository"
+	"github.com/egorkos/minesweeper/app/interface/persistence/ids"
+	"github.com/egorkos/minesweeper/app/interface/persistence/repotest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGameRepository(t *testing.T) {
+	repotest.GameRepository(t, func(t *testing.T) repository.GameRepository {
+		repo, err := NewGameRepository(filepath.Join(t.TempDir(), "games.db"), ids.NewSequentialAllocator())
+		assert.Nil(t, err)
+		t.Cleanup(func() { repo.Close() })
+		return repo
+	})
+}
+
+func TestGameRepositoryReopen(t *testing.T) {
+	now := time.Now()
+	created := model.Event{
+		Kind:     model.CreatedEvent,
+		Time:     now,
+		Settings: &model.GameSettings{Rows: 2, Cols: 2, Mines: 1},
+		Mines:    []model.Coordinate{{Row: 1, Col: 1}},
+	}
+	cases := []struct {
+		name      string
+		ID        string
+		events    []model.Event
+		seqs      []int
+		expStatus model.GameStatus
+	}{
+		{
+			name:      "OK/SAVE",
+			ID:        "",
+			events:    []model.Event{created},
+			seqs:      []int{1},
+			expStatus: model.Running,
+		},
+		{
+			name: "OK/UPDATE",
+			ID:   "1",
+			events: []model.Event{
+				model.NewCellEvent(model.FlaggedEvent, 1, 1, now),
+				model.NewCellEvent(model.RevealedEvent, 0, 0, now),
+				model.NewFinishedEvent(model.BoardCleared, model.PlayerActor, now),
+			},
+			seqs:      []int{1, 2, 3, 4},
+			expStatus: model.Win,
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "games.db")
+	repo, err := NewGameRepository(path, ids.NewSequentialAllocator())
+	assert.Nil(t, err)
+	defer repo.Close()
+	game := &model.Game{}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.ID, game.ID)
+			for _, event := range c.events {
+				game.Apply(event)
+			}
+
+			err := repo.Append(game, c.events...)
+			assert.Nil(t, err)
+			assert.Equal(t, "1", game.ID)
+
+			var status model.GameStatus
+			assert.Nil(t, repo.db.QueryRow("SELECT status FROM games WHERE id = 1").Scan(&status))
+			assert.Equal(t, c.expStatus, status)
+
+			events, err := repo.FindEvents("1")
+			assert.Nil(t, err)
+			assert.Len(t, events, len(c.seqs))
+			for i, event := range events {
+				assert.Equal(t, "1", event.GameID)
+				assert.Equal(t, c.seqs[i], event.Seq)
+			}
+		})
+	}
+
+	t.Run("OK/REOPEN", func(t *testing.T) {
+		repo.Close()
+		reopened, err := NewGameRepository(path, ids.NewSequentialAllocator())
+		assert.Nil(t, err)
+		defer reopened.Close()
+
+		saved, findErr := reopened.FindByID("1")
+		assert.Nil(t, findErr)
+		assert.Equal(t, model.Win, saved.Status)
+		assert.Equal(t, game.CellsRevealed, saved.CellsRevealed)
+		assert.Len(t, saved.Moves, len(game.Moves))
+		assert.True(t, game.StartTime.Equal(saved.StartTime))
+
+		var version int
+		assert.Nil(t, reopened.db.QueryRow("PRAGMA user_version").Scan(&version))
+		assert.Equal(t, len(migrations), version)
+
+		next := &model.Game{}
+		next.Apply(created)
+		assert.Nil(t, reopened.Append(next, created))
+		assert.Equal(t, "2", next.ID)
+
+		games, findErr := reopened.FindAll()
+		assert.Nil(t, findErr)
+		assert.Len(t, games, 2)
+	})
+}
+
+func TestGameRepositoryQueryPlans(t *testing.T) {
+	cases := []struct {
+		name  string
+		query string
+		args  []interface{}
+		index string
+	}{
+		{
+			name:  "OK/FIND_ALL",
+			query: findAllQuery,
+			index: "games_start_time",
+		},
+		{
+			name:  "OK/FIND_UNFINISHED",
+			query: findUnfinishedQuery,
+			args:  []interface{}{int(model.Running), int(model.Paused)},
+			index: "games_status",
+		},
+	}
+
+	repo, err := NewGameRepository(filepath.Join(t.TempDir(), "games.db"), ids.NewSequentialAllocator())
+	assert.Nil(t, err)
+	defer repo.Close()
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rows, err := repo.db.Query("EXPLAIN QUERY PLAN "+c.query, c.args...)
+			assert.Nil(t, err)
+			defer rows.Close()
+
+			var plan []string
+			for rows.Next() {
+				var id, parent, unused int
+				var detail string
+				assert.Nil(t, rows.Scan(&id, &parent, &unused, &detail))
+				plan = append(plan, detail)
+			}
+			assert.Contains(t, strings.Join(plan, "\n"), c.index)
+		})
+	}
+}
+
+// TestGameRepositoryMigrate opens a database holding games with a new
+// migration, checking they are kept and only the new migration runs.
+func TestGameRepositoryMigrate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "games.db")
+	repo, err := NewGameRepository(path, ids.NewSequentialAllocator())
+	assert.Nil(t, err)
+	game := &model.Game{}
+	created := model.NewCreatedEvent(&model.Game{Rows: 2, Cols: 2, Mines: 1}, time.Now())
+	game.Apply(created)
+	assert.Nil(t, repo.Append(game, created))
+	repo.Close()
+
+	released := migrations
+	defer func() { migrations = released }()
+	migrations = append(append([]string(nil), released...), `CREATE TABLE migrated (id INTEGER);
+		INSERT INTO migrated SELECT COUNT(*) FROM games;`)
+
+	migrated, err := NewGameRepository(path, ids.NewSequentialAllocator())
+	assert.Nil(t, err)
+	defer migrated.Close()
+
+	var version, games int
+	assert.Nil(t, migrated.db.QueryRow("PRAGMA user_version").Scan(&version))
+	assert.Equal(t, len(migrations), version)
+	assert.Nil(t, migrated.db.QueryRow("SELECT id FROM migrated").Scan(&games))
+	assert.Equal(t, 1, games)
+
+	saved, findErr := migrated.FindByID(game.ID)
+	assert.Nil(t, findErr)
+	assert.Equal(t, game.Version, saved.Version)
+	events, findErr := migrated.FindEvents(game.ID)
+	assert.Nil(t, findErr)
+	assert.Len(t, events, 1)
+}
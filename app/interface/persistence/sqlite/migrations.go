@@ -0,0 +1,66 @@
+package sqlite
+
+import (
+	"database/sql"
+	"strconv"
+)
+
+// migrations are the schema changes, in order. The database keeps how many
+// were applied in its user_version, so only the new ones run on start. A
+// migration is never changed once released, changes go in a new one.
+var migrations = []string{
+	// Games are only looked up by status and listed by start time
+	`CREATE TABLE games (
+		id             TEXT PRIMARY KEY,
+		status         INTEGER NOT NULL,
+		finish_reason  TEXT,
+		board_rows     INTEGER NOT NULL,
+		board_cols     INTEGER NOT NULL,
+		mines          INTEGER NOT NULL,
+		preset         TEXT,
+		practice       BOOLEAN NOT NULL,
+		start_time     TEXT,
+		finish_time    TEXT,
+		last_move_time TEXT
+	);
+	CREATE INDEX games_status ON games (status);
+	CREATE INDEX games_start_time ON games (start_time);
+	CREATE TABLE events (
+		game_id TEXT NOT NULL REFERENCES games (id),
+		seq     INTEGER NOT NULL,
+		kind    TEXT NOT NULL,
+		time    TEXT NOT NULL,
+		data    TEXT NOT NULL,
+		PRIMARY KEY (game_id, seq)
+	);`,
+}
+
+// migrate applies the migrations the database doesn't have yet, each one
+// in its own transaction along with the version bump.
+func migrate(db *sql.DB) error {
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return err
+	}
+
+	for ; version < len(migrations); version++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(migrations[version]); err != nil {
+			tx.Rollback()
+			return err
+		}
+		// PRAGMA doesn't take parameters
+		if _, err := tx.Exec("PRAGMA user_version = " + strconv.Itoa(version+1)); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -1,19 +1,49 @@
 package server
 
 import (
+	"crypto/subtle"
+	"net/http"
+	"os"
+
 	"github.com/Sirupsen/logrus"
 	"github.com/egorkos/minesweeper/app/registry"
 	"github.com/gin-gonic/gin"
 )
 
+const (
+	AdminTokenHeader = "X-Admin-Token"
+	AdminOnly        = "Admin only"
+)
+
 func InjectContainer() gin.HandlerFunc {
 	logrus.Debug("Starting container")
 	ctn, err := registry.NewContainer()
 	if err != nil {
 		logrus.Fatalf("failed to build container: %v", err)
 	}
+	ctn.Resolve("expiry-worker")
 	return func(c *gin.Context) {
 		c.Set("ctn", ctn)
 		c.Next()
 	}
 }
+
+// RequireAdmin only lets through requests carrying the ADMIN_TOKEN
+// environment variable in the X-Admin-Token header. Admin routes are
+// closed when ADMIN_TOKEN is not set.
+func RequireAdmin() gin.HandlerFunc {
+	token := os.Getenv("ADMIN_TOKEN")
+	if token == "" {
+		logrus.Warn("ADMIN_TOKEN not set, admin routes are disabled")
+	}
+	return func(c *gin.Context) {
+		// The comparison takes the same time wherever the header differs,
+		// so the token can't be guessed a byte at a time
+		given := c.GetHeader(AdminTokenHeader)
+		if token == "" || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusForbidden, AdminOnly)
+			return
+		}
+		c.Next()
+	}
+}
@@ -22,7 +22,24 @@ func initializeRoutes(router *gin.Engine) {
 
 	router.POST("/game", controller.CreateGame)
 	router.GET("/games/:id", controller.GetGame)
-	router.GET("/games", controller.ListGames)
 	router.POST("/games/:id/reveal", controller.Reveal)
 	router.POST("/games/:id/flag", controller.Flag)
+	router.POST("/games/:id/chord", controller.Chord)
+	router.POST("/games/:id/pause", controller.Pause)
+	router.POST("/games/:id/resume", controller.Resume)
+	router.POST("/games/:id/resign", controller.Resign)
+	router.POST("/games/:id/undo", controller.Undo)
+	router.POST("/games/:id/restart", controller.Restart)
+	router.GET("/games/:id/events", controller.ListEvents)
+	router.GET("/games/:id/moves", controller.ListMoves)
+	router.GET("/games/:id/replay", controller.Replay)
+	router.POST("/games/:id/hint", controller.Hint)
+	router.GET("/games/:id/probabilities", controller.Probabilities)
+	router.POST("/games/:id/autosolve", controller.Autosolve)
+	router.GET("/presets", controller.ListPresets)
+
+	admin := router.Group("/admin", RequireAdmin())
+	admin.POST("/presets", controller.SavePreset)
+	admin.GET("/games", controller.ListGames)
+	admin.GET("/games/:id", controller.GetFullGame)
 }
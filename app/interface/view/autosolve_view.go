@@ -0,0 +1,20 @@
+package view
+
+import "github.com/egorkos/minesweeper/app/domain/model"
+
+// AutosolveView is the moves played by an autosolve along with the game
+// they left.
+type AutosolveView struct {
+	Moves []model.Move `json:"moves"`
+	Game  GameView     `json:"game"`
+}
+
+func NewAutosolveView(game *model.Game, moves []model.Move) AutosolveView {
+	if moves == nil {
+		moves = []model.Move{}
+	}
+	return AutosolveView{
+		Moves: moves,
+		Game:  NewGameView(game),
+	}
+}
@@ -0,0 +1,12 @@
+package view
+
+import "github.com/egorkos/minesweeper/app/interface/apierr"
+
+// NewErrorView is what an error responds with: its message, or the whole
+// error on version conflicts, so clients read the current version from it.
+func NewErrorView(apiError *apierr.ApiError) interface{} {
+	if apiError.Version != nil {
+		return apiError
+	}
+	return apiError.Error()
+}
@@ -0,0 +1,38 @@
+package view
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/egorkos/minesweeper/app/domain/repository"
+	"github.com/egorkos/minesweeper/app/interface/apierr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewErrorView(t *testing.T) {
+	cases := []struct {
+		name    string
+		err     *apierr.ApiError
+		expJSON string
+	}{
+		{
+			name:    "OK/MESSAGE",
+			err:     apierr.NewAPIError("Game Not Found", http.StatusNotFound),
+			expJSON: `"Game Not Found"`,
+		},
+		{
+			name:    "OK/VERSION_CONFLICT",
+			err:     repository.NewVersionConflict(3),
+			expJSON: `{"message":"Game was changed by another request, current version is 3","status":409,"version":3}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			data, err := json.Marshal(NewErrorView(c.err))
+			assert.Nil(t, err)
+			assert.Equal(t, c.expJSON, string(data))
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package view
+
+import "github.com/egorkos/minesweeper/app/domain/model"
+
+// EventView is a game event as players see it. The mine layout and the
+// seed are masked as on GameView.
+type EventView struct {
+	model.Event
+	Settings *model.GameSettings `json:"settings,omitempty"`
+	Mines    []model.Coordinate  `json:"mines,omitempty"`
+}
+
+func NewEventViews(game *model.Game, events []model.Event) []EventView {
+	masked := !game.LayoutShown()
+
+	views := make([]EventView, len(events))
+	for i, event := range events {
+		views[i] = EventView{
+			Event:    event,
+			Settings: event.Settings,
+			Mines:    event.Mines,
+		}
+		if masked {
+			views[i].Mines = nil
+			if event.Settings != nil {
+				settings := *event.Settings
+				settings.Seed = 0
+				views[i].Settings = &settings
+			}
+		}
+	}
+	return views
+}
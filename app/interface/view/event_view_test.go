@@ -0,0 +1,58 @@
+package view
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/egorkos/minesweeper/app/domain/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewEventViews(t *testing.T) {
+	cases := []struct {
+		name    string
+		status  model.GameStatus
+		expJSON string
+	}{
+		{
+			name:    "OK/RUNNING_MASKED",
+			status:  model.Running,
+			expJSON: `{"rows":1,"cols":3,"mines":1,"wrap":false,"first_click_safe":false,"safe_neighbours":false,"no_guess":false,"practice":false}`,
+		},
+		{
+			name:    "OK/FINISHED_FULL",
+			status:  model.Loose,
+			expJSON: `{"rows":1,"cols":3,"mines":1,"wrap":false,"seed":42,"first_click_safe":false,"safe_neighbours":false,"no_guess":false,"practice":false}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			game := &model.Game{Status: c.status}
+			events := []model.Event{
+				{
+					Kind:     model.CreatedEvent,
+					Time:     time.Now(),
+					Settings: &model.GameSettings{Rows: 1, Cols: 3, Mines: 1, Seed: 42},
+					Mines:    []model.Coordinate{{Row: 0, Col: 1}},
+				},
+			}
+
+			data, err := json.Marshal(NewEventViews(game, events))
+			assert.Nil(t, err)
+
+			var fields []map[string]json.RawMessage
+			assert.Nil(t, json.Unmarshal(data, &fields))
+			assert.Equal(t, c.expJSON, string(fields[0]["settings"]))
+			assert.Equal(t, `"created"`, string(fields[0]["kind"]))
+
+			mines, exists := fields[0]["mines"]
+			assert.Equal(t, c.status != model.Running, exists)
+			if exists {
+				assert.Equal(t, `[{"row":0,"col":1}]`, string(mines))
+			}
+			assert.Equal(t, int64(42), events[0].Settings.Seed)
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package view
+
+import (
+	"time"
+
+	"github.com/egorkos/minesweeper/app/domain/model"
+)
+
+// Board characters, one per cell. Revealed cells without a mine show how
+// many mines they have around, from '0'.
+const (
+	hiddenCell   = '.'
+	flaggedCell  = 'F'
+	questionCell = '?'
+	revealedMine = '*'
+	revealedCell = '0'
+)
+
+// GameView is the game as players see it. Until the game is finished the
+// content of hidden cells and the seed, that would rebuild the layout, are
+// left out. Finished games show everything, but for practice games lost
+// on a mine, that can still undo the losing reveal. Along with the grid, the board
+// goes as a string per row with a character per cell, cheaper to read on
+// large boards.
+type GameView struct {
+	model.Game
+	Seed    *int64        `json:"seed,omitempty"`
+	Elapsed time.Duration `json:"elapsed"`
+	// Moves leaves the move history out, it grows with every move and is
+	// paged on its own endpoint
+	Moves *struct{}    `json:"moves,omitempty"`
+	Grid  [][]CellView `json:"grid,omitempty"`
+	Board []string     `json:"board"`
+}
+
+// CellView leaves Mine and MinesAround out while the cell is hidden.
+type CellView struct {
+	Mine        *bool      `json:"mine,omitempty"`
+	Revealed    bool       `json:"revealed"`
+	Mark        model.Mark `json:"mark"`
+	MinesAround *uint8     `json:"mines_around,omitempty"`
+}
+
+func NewGameView(game *model.Game) GameView {
+	return newGameView(game, !game.LayoutShown(), time.Now())
+}
+
+// NewFullGameView shows everything, even on unfinished games.
+func NewFullGameView(game *model.Game) GameView {
+	return newGameView(game, false, time.Now())
+}
+
+func newGameView(game *model.Game, masked bool, now time.Time) GameView {
+	view := GameView{
+		Game:    *game,
+		Elapsed: game.Elapsed(now),
+		Grid:    make([][]CellView, len(game.Grid)),
+		Board:   make([]string, len(game.Grid)),
+	}
+	if !masked {
+		view.Seed = &game.Seed
+	}
+
+	cells := make([]CellView, game.Rows*game.Cols)
+	row := make([]byte, game.Cols)
+	for x := range game.Grid {
+		view.Grid[x] = cells[x*game.Cols : (x+1)*game.Cols : (x+1)*game.Cols]
+		for y := range game.Grid[x] {
+			view.Grid[x][y] = newCellView(&game.Grid[x][y], masked)
+			row[y] = boardCell(&game.Grid[x][y])
+		}
+		view.Board[x] = string(row[:len(game.Grid[x])])
+	}
+
+	return view
+}
+
+func NewGameViews(games []*model.Game) []GameView {
+	views := make([]GameView, len(games))
+	for i, game := range games {
+		views[i] = NewGameView(game)
+	}
+	return views
+}
+
+func newCellView(cell *model.Cell, masked bool) CellView {
+	view := CellView{
+		Revealed: cell.Revealed,
+		Mark:     cell.Mark,
+	}
+	if !masked || cell.Revealed {
+		view.Mine = &cell.Mine
+		view.MinesAround = &cell.MinesAround
+	}
+	return view
+}
+
+// boardCell is the character of a cell as the player sees it.
+func boardCell(cell *model.Cell) byte {
+	switch {
+	case cell.Revealed && cell.Mine:
+		return revealedMine
+	case cell.Revealed:
+		return revealedCell + cell.MinesAround
+	case cell.Mark == model.Flag:
+		return flaggedCell
+	case cell.Mark == model.Question:
+		return questionCell
+	default:
+		return hiddenCell
+	}
+}
@@ -0,0 +1,84 @@
+package view
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/egorkos/minesweeper/app/domain/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewGameView(t *testing.T) {
+	cases := []struct {
+		name    string
+		status  model.GameStatus
+		full    bool
+		expJSON string
+	}{
+		{
+			name:    "OK/RUNNING_MASKED",
+			status:  model.Running,
+			expJSON: `[{"mine":false,"revealed":true,"mark":"none","mines_around":1},{"revealed":false,"mark":"flag"},{"revealed":false,"mark":"question"}]`,
+		},
+		{
+			name:    "OK/RUNNING_FULL",
+			status:  model.Running,
+			full:    true,
+			expJSON: `[{"mine":false,"revealed":true,"mark":"none","mines_around":1},{"mine":true,"revealed":false,"mark":"flag","mines_around":0},{"mine":false,"revealed":false,"mark":"question","mines_around":1}]`,
+		},
+		{
+			name:    "OK/FINISHED_FULL",
+			status:  model.Loose,
+			expJSON: `[{"mine":false,"revealed":true,"mark":"none","mines_around":1},{"mine":true,"revealed":false,"mark":"flag","mines_around":0},{"mine":false,"revealed":false,"mark":"question","mines_around":1}]`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			game := &model.Game{
+				Rows:  2,
+				Cols:  3,
+				Mines: 2,
+				Seed:  42,
+				Grid: [][]model.Cell{
+					{
+						{Revealed: true, MinesAround: 1},
+						{Mine: true, Mark: model.Flag},
+						{MinesAround: 1, Mark: model.Question},
+					},
+					{
+						{Mine: true, Revealed: true},
+						{MinesAround: 2},
+						{MinesAround: 1},
+					},
+				},
+				Moves:  []model.Move{{Kind: model.RevealMove}},
+				Status: c.status,
+			}
+
+			view := NewGameView(game)
+			if c.full {
+				view = NewFullGameView(game)
+			}
+			data, err := json.Marshal(view)
+			assert.Nil(t, err)
+
+			var fields map[string]json.RawMessage
+			assert.Nil(t, json.Unmarshal(data, &fields))
+			var grid []json.RawMessage
+			assert.Nil(t, json.Unmarshal(fields["grid"], &grid))
+			assert.Len(t, grid, 2)
+			assert.Equal(t, c.expJSON, string(grid[0]))
+			assert.Equal(t, `["1F?","*.."]`, string(fields["board"]))
+			assert.Equal(t, "3", string(fields["cols"]))
+			_, exists := fields["moves"]
+			assert.False(t, exists)
+
+			seed, exists := fields["seed"]
+			assert.Equal(t, c.full || c.status != model.Running, exists)
+			if exists {
+				assert.Equal(t, "42", string(seed))
+			}
+		})
+	}
+}
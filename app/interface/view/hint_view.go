@@ -0,0 +1,19 @@
+package view
+
+import (
+	"github.com/egorkos/minesweeper/app/domain/model"
+	"github.com/egorkos/minesweeper/app/domain/solver"
+)
+
+// HintView is a hint along with the game it was asked on.
+type HintView struct {
+	Hint solver.Hint `json:"hint"`
+	Game GameView    `json:"game"`
+}
+
+func NewHintView(game *model.Game, hint solver.Hint) HintView {
+	return HintView{
+		Hint: hint,
+		Game: NewGameView(game),
+	}
+}
@@ -0,0 +1,21 @@
+package view
+
+import "github.com/egorkos/minesweeper/app/domain/model"
+
+// MovesView is a page of a game move history, oldest first.
+type MovesView struct {
+	Offset int          `json:"offset"`
+	Total  int          `json:"total"`
+	Moves  []model.Move `json:"moves"`
+}
+
+func NewMovesView(offset, total int, moves []model.Move) MovesView {
+	if moves == nil {
+		moves = []model.Move{}
+	}
+	return MovesView{
+		Offset: offset,
+		Total:  total,
+		Moves:  moves,
+	}
+}
@@ -0,0 +1,32 @@
+package view
+
+import "github.com/egorkos/minesweeper/app/domain/model"
+
+// ProbabilitiesView is the board as a matrix of the probability of each
+// cell being a mine, null on revealed cells.
+type ProbabilitiesView struct {
+	ID    string       `json:"id"`
+	Rows  int          `json:"rows"`
+	Cols  int          `json:"cols"`
+	Mines int          `json:"mines"`
+	Grid  [][]*float64 `json:"grid"`
+}
+
+func NewProbabilitiesView(game *model.Game, probabilities [][]float64) ProbabilitiesView {
+	view := ProbabilitiesView{
+		ID:    game.ID,
+		Rows:  game.Rows,
+		Cols:  game.Cols,
+		Mines: game.Mines,
+		Grid:  make([][]*float64, len(probabilities)),
+	}
+	for x := range probabilities {
+		view.Grid[x] = make([]*float64, len(probabilities[x]))
+		for y := range probabilities[x] {
+			if !game.Grid[x][y].Revealed {
+				view.Grid[x][y] = &probabilities[x][y]
+			}
+		}
+	}
+	return view
+}
@@ -0,0 +1,45 @@
+package view
+
+import (
+	"time"
+
+	"github.com/egorkos/minesweeper/app/domain/model"
+)
+
+// ReplayView is a game as it was after a replay step. The board is masked
+// the same as its game still is.
+type ReplayView struct {
+	Step  int       `json:"step"`
+	Steps int       `json:"steps"`
+	Move  *StepView `json:"move,omitempty"`
+	Game  GameView  `json:"game"`
+}
+
+// StepView is the move or undo a replay step was made of.
+type StepView struct {
+	Action model.EventKind   `json:"action"`
+	Cell   *model.Coordinate `json:"cell,omitempty"`
+	Mark   model.Mark        `json:"mark,omitempty"`
+	Moves  int               `json:"moves,omitempty"`
+	Time   time.Time         `json:"time"`
+}
+
+func NewReplayView(game *model.Game, replay *model.Replay) ReplayView {
+	now := replay.Game.StartTime
+	view := ReplayView{
+		Step:  replay.Step,
+		Steps: replay.Steps,
+	}
+	if event := replay.Event; event != nil {
+		now = event.Time
+		view.Move = &StepView{
+			Action: event.Kind,
+			Cell:   event.Cell,
+			Mark:   event.Mark,
+			Moves:  event.Moves,
+			Time:   event.Time,
+		}
+	}
+	view.Game = newGameView(replay.Game, !game.LayoutShown(), now)
+	return view
+}
@@ -0,0 +1,54 @@
+package worker
+
+import (
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/egorkos/minesweeper/app/usecase"
+)
+
+// ExpiryWorker periodically ends the games that ran out of time, so their
+// status is right even if no player asks for them again.
+type ExpiryWorker struct {
+	useCase  usecase.GameUsecase
+	interval time.Duration
+	stop     chan struct{}
+}
+
+func NewExpiryWorker(useCase usecase.GameUsecase, interval time.Duration) *ExpiryWorker {
+	return &ExpiryWorker{
+		useCase:  useCase,
+		interval: interval,
+		stop:     make(chan struct{}),
+	}
+}
+
+func (w *ExpiryWorker) Start() {
+	ticker := time.NewTicker(w.interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				w.Run()
+			case <-w.stop:
+				return
+			}
+		}
+	}()
+}
+
+func (w *ExpiryWorker) Run() {
+	failed, err := w.useCase.ExpireGames()
+	if err != nil {
+		logrus.Errorf("failed to expire games: %v", err)
+		return
+	}
+	for ID, err := range failed {
+		logrus.Errorf("failed to expire game %s: %v", ID, err)
+	}
+}
+
+func (w *ExpiryWorker) Stop() {
+	close(w.stop)
+}
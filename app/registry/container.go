@@ -1,12 +1,28 @@
 package registry
 
 import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/egorkos/minesweeper/app/domain/repository"
 	"github.com/egorkos/minesweeper/app/domain/service"
+	"github.com/egorkos/minesweeper/app/interface/persistence/file"
+	"github.com/egorkos/minesweeper/app/interface/persistence/ids"
 	"github.com/egorkos/minesweeper/app/interface/persistence/memory"
+	"github.com/egorkos/minesweeper/app/interface/persistence/sqlite"
+	"github.com/egorkos/minesweeper/app/interface/worker"
 	"github.com/egorkos/minesweeper/app/usecase"
 	"github.com/sarulabs/di"
 )
 
+const (
+	defaultIdleTimeout = 24 * time.Hour
+	defaultGamesDir    = "data/games"
+	defaultGamesDB     = "data/games.db"
+)
+
 type Container struct {
 	ctn di.Container
 }
@@ -17,10 +33,36 @@ func NewContainer() (*Container, error) {
 		return nil, err
 	}
 	if err := builder.Add([]di.Def{
+		{
+			Name:  "game-repository",
+			Build: buildGameRepository,
+			Close: func(obj interface{}) error {
+				if closer, ok := obj.(io.Closer); ok {
+					return closer.Close()
+				}
+				return nil
+			},
+		},
+		{
+			Name:  "preset-repository",
+			Build: buildPresetRepository,
+		},
 		{
 			Name:  "game-usecase",
 			Build: buildGameUsecase,
 		},
+		{
+			Name:  "preset-usecase",
+			Build: buildPresetUsecase,
+		},
+		{
+			Name:  "expiry-worker",
+			Build: buildExpiryWorker,
+			Close: func(obj interface{}) error {
+				obj.(*worker.ExpiryWorker).Stop()
+				return nil
+			},
+		},
 	}...); err != nil {
 		return nil, err
 	}
@@ -34,8 +76,82 @@ func (c *Container) Resolve(name string) interface{} {
 func (c *Container) Clean() error {
 	return c.ctn.Clean()
 }
+
+// buildGameRepository picks where games are stored from GAME_REPOSITORY:
+// "memory", the default, "file", on the GAMES_DIR directory, or "sqlite",
+// on the GAMES_DB database file. GAME_IDS picks the IDs of new games:
+// "sequential" numbers, the default, or opaque "ulid" ones.
+func buildGameRepository(ctn di.Container) (interface{}, error) {
+	kind := os.Getenv("GAME_IDS")
+	if kind == "" {
+		kind = ids.Sequential
+	}
+	allocator, err := ids.NewAllocator(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	switch kind := os.Getenv("GAME_REPOSITORY"); kind {
+	case "", "memory":
+		return memory.NewGameRepository(allocator), nil
+	case "file":
+		repo, err := file.NewGameRepository(gamesDir(), allocator)
+		if err != nil {
+			return nil, err
+		}
+		return repo, nil
+	case "sqlite":
+		repo, err := sqlite.NewGameRepository(gamesDB(), allocator)
+		if err != nil {
+			return nil, err
+		}
+		return repo, nil
+	default:
+		return nil, fmt.Errorf("unknown game repository %q", kind)
+	}
+}
+func gamesDir() string {
+	if dir := os.Getenv("GAMES_DIR"); dir != "" {
+		return dir
+	}
+	return defaultGamesDir
+}
+func gamesDB() string {
+	if path := os.Getenv("GAMES_DB"); path != "" {
+		return path
+	}
+	return defaultGamesDB
+}
+func buildPresetRepository(ctn di.Container) (interface{}, error) {
+	return memory.NewPresetRepository(), nil
+}
 func buildGameUsecase(ctn di.Container) (interface{}, error) {
-	repo := memory.NewGameRepository()
+	repo := ctn.Get("game-repository").(repository.GameRepository)
+	presets := ctn.Get("preset-repository").(repository.PresetRepository)
 	service := service.NewGameService(repo)
-	return usecase.NewGameUsecase(repo, service), nil
+	timeout, err := idleTimeout()
+	if err != nil {
+		return nil, err
+	}
+	return usecase.NewGameUsecase(repo, presets, service, timeout), nil
+}
+
+// idleTimeout reads how long unfinished games may go without moves before
+// being abandoned from IDLE_TIMEOUT, e.g. "30m". "0" never abandons them.
+func idleTimeout() (time.Duration, error) {
+	value := os.Getenv("IDLE_TIMEOUT")
+	if value == "" {
+		return defaultIdleTimeout, nil
+	}
+	return time.ParseDuration(value)
+}
+func buildPresetUsecase(ctn di.Container) (interface{}, error) {
+	repo := ctn.Get("preset-repository").(repository.PresetRepository)
+	return usecase.NewPresetUsecase(repo), nil
+}
+func buildExpiryWorker(ctn di.Container) (interface{}, error) {
+	useCase := ctn.Get("game-usecase").(usecase.GameUsecase)
+	worker := worker.NewExpiryWorker(useCase, time.Second)
+	worker.Start()
+	return worker, nil
 }
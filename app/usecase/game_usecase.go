@@ -7,85 +7,376 @@ import (
 	"github.com/egorkos/minesweeper/app/domain/model"
 	"github.com/egorkos/minesweeper/app/domain/repository"
 	"github.com/egorkos/minesweeper/app/domain/service"
+	"github.com/egorkos/minesweeper/app/domain/solver"
 	"github.com/egorkos/minesweeper/app/interface/apierr"
 )
 
 const (
-	CantRevealAFlaggedCell          = "Can't reveal a flagged cell"
-	CantUpdateCellsOnAFinishedGame  = "Can't update cells on a finished game"
-	RowValueExceededGridLimits      = "Row value exceeded grid limits"
-	ColValueExceededGridLimits      = "Col value exceeded grid limits"
-	CantUpdateAnAlreadyRevealedCell = "Can't update an already revealed cell"
+	CantRevealAFlaggedCell           = "Can't reveal a flagged cell"
+	CantUpdateCellsOnAFinishedGame   = "Can't update cells on a finished game"
+	RowValueExceededGridLimits       = "Row value exceeded grid limits"
+	ColValueExceededGridLimits       = "Col value exceeded grid limits"
+	CantUpdateAnAlreadyRevealedCell  = "Can't update an already revealed cell"
+	UnknownPreset                    = "Unknown preset"
+	CantChordAHiddenCell             = "Can't chord a hidden cell"
+	FlagsAroundDontMatchMinesAround  = "Flags around don't match the mines around"
+	CantUpdateCellsOnAPausedGame     = "Can't update cells on a paused game"
+	OnlyRunningGamesCanBePaused      = "Only running games can be paused"
+	OnlyPausedGamesCanBeResumed      = "Only paused games can be resumed"
+	TimedGamesCantBePaused           = "Timed games can't be paused"
+	GameTimeLimitExceeded            = "Game time limit exceeded"
+	OnlyUnfinishedGamesCanBeResigned = "Only running or paused games can be resigned"
+	MovesToUndoMustBePositive        = "Moves to undo must be positive"
+	NotEnoughMovesToUndo             = "Not enough moves to undo"
+	OnlyPracticeGamesCanUndoALoss    = "Only practice games can undo a losing reveal"
+	ReplayStepOutOfRange             = "Replay step out of range"
+	NoHiddenCellToHint               = "No hidden cell left to hint"
+	TooManyUndeterminedCells         = "Too many undetermined cells to compute the probabilities"
+	FlagsDontMatchTheRevealedNumbers = "The flags don't match the revealed numbers"
+	OnlyFinishedGamesCanBeRestarted  = "Only finished games can be restarted"
+	PracticeLossesCantBeRestarted    = "Practice games lost on a mine can't be restarted, undo the losing reveal instead"
+	MovesOffsetMustNotBeNegative     = "The moves offset must not be negative"
+	MovesLimitOutOfRange             = "The moves limit must be between 1 and 1000"
+
+	// MaxMovesPage bounds the moves returned at once
+	MaxMovesPage = 1000
 )
 
 type GameUsecase interface {
 	StartGame(game model.Game) (model.Game, *apierr.ApiError)
 	FindAll() ([]*model.Game, *apierr.ApiError)
-	FindByID(id int) (*model.Game, *apierr.ApiError)
-	Reveal(ID, row, col int) (*model.Game, *apierr.ApiError)
-	Flag(ID, row, col int) (*model.Game, *apierr.ApiError)
+	FindByID(id string) (*model.Game, *apierr.ApiError)
+	Reveal(ID string, row, col int) (*model.Game, *apierr.ApiError)
+	Flag(ID string, row, col int) (*model.Game, *apierr.ApiError)
+	Chord(ID string, row, col int) (*model.Game, *apierr.ApiError)
+	Pause(ID string) (*model.Game, *apierr.ApiError)
+	Resume(ID string) (*model.Game, *apierr.ApiError)
+	Resign(ID string) (*model.Game, *apierr.ApiError)
+	Undo(ID string, moves int) (*model.Game, *apierr.ApiError)
+	Restart(ID string) (*model.Game, *apierr.ApiError)
+	Events(ID string) ([]model.Event, *apierr.ApiError)
+	Moves(ID string, offset, limit int) ([]model.Move, int, *apierr.ApiError)
+	Replay(ID string, step int) (*model.Game, *model.Replay, *apierr.ApiError)
+	Hint(ID string) (*model.Game, solver.Hint, *apierr.ApiError)
+	Probabilities(ID string) (*model.Game, [][]float64, *apierr.ApiError)
+	Autosolve(ID string) (*model.Game, []model.Move, *apierr.ApiError)
+	ExpireGames() (map[string]*apierr.ApiError, *apierr.ApiError)
 }
 
 type gameUsecase struct {
-	repo    repository.GameRepository
-	service *service.GameService
+	repo        repository.GameRepository
+	presets     repository.PresetRepository
+	service     *service.GameService
+	idleTimeout time.Duration
 }
 
-func NewGameUsecase(repo repository.GameRepository, service *service.GameService) *gameUsecase {
+// NewGameUsecase builds the game usecase. Unfinished games without moves
+// for idleTimeout are abandoned, a zero idleTimeout keeps them forever.
+func NewGameUsecase(repo repository.GameRepository, presets repository.PresetRepository, service *service.GameService, idleTimeout time.Duration) *gameUsecase {
 	return &gameUsecase{
-		repo:    repo,
-		service: service,
+		repo:        repo,
+		presets:     presets,
+		service:     service,
+		idleTimeout: idleTimeout,
 	}
 }
 
 func (g *gameUsecase) StartGame(game model.Game) (model.Game, *apierr.ApiError) {
+	if game.Preset != "" {
+		preset, err := g.findPreset(game.Preset)
+		if err != nil {
+			return model.Game{}, err
+		}
+		preset.Apply(&game)
+		if err := game.ValidateBoard(); err != nil {
+			return model.Game{}, apierr.NewAPIError(err.Error(), http.StatusBadRequest)
+		}
+	}
+
 	newGame := g.service.StartGame(game)
-	g.repo.Upsert(&newGame)
-	return newGame, nil
+	var created model.Game
+	events := play(&created, nil, model.NewCreatedEvent(&newGame, newGame.StartTime))
+
+	err := g.repo.Append(&created, events...)
+	if err != nil {
+		return model.Game{}, err
+	}
+
+	return created, nil
+}
+
+func (g *gameUsecase) Restart(ID string) (*model.Game, *apierr.ApiError) {
+	source, err := g.FindByID(ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The restart shows its layout once it ends, which must not happen
+	// while the source is still being played
+	if !source.Status.Finished() {
+		return nil, apierr.NewAPIError(OnlyFinishedGamesCanBeRestarted, http.StatusBadRequest)
+	}
+	if source.CanUndoLoss() {
+		return nil, apierr.NewAPIError(PracticeLossesCantBeRestarted, http.StatusBadRequest)
+	}
+
+	newGame := g.service.RestartGame(source)
+	var game model.Game
+	events := play(&game, nil, model.NewCreatedEvent(&newGame, newGame.StartTime))
+
+	err = g.repo.Append(&game, events...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &game, nil
+}
+
+// Events returns every change stored for a game, oldest first.
+func (g *gameUsecase) Events(ID string) ([]model.Event, *apierr.ApiError) {
+	_, err := g.FindByID(ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.repo.FindEvents(ID)
+}
+
+// Moves returns up to limit moves of the game history from the offset on,
+// oldest first, along with how many moves the game has.
+func (g *gameUsecase) Moves(ID string, offset, limit int) ([]model.Move, int, *apierr.ApiError) {
+	if offset < 0 {
+		return nil, 0, apierr.NewAPIError(MovesOffsetMustNotBeNegative, http.StatusBadRequest)
+	}
+
+	if limit < 1 || limit > MaxMovesPage {
+		return nil, 0, apierr.NewAPIError(MovesLimitOutOfRange, http.StatusBadRequest)
+	}
+
+	game, err := g.FindByID(ID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total := len(game.Moves)
+	if offset > total {
+		offset = total
+	}
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+
+	return game.Moves[offset:end], total, nil
+}
+
+// Replay returns the game along with a replay of it moved to the given
+// step.
+func (g *gameUsecase) Replay(ID string, step int) (*model.Game, *model.Replay, *apierr.ApiError) {
+	game, err := g.FindByID(ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	events, err := g.repo.FindEvents(ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	replay := model.NewReplay(events)
+	if step < 0 || step > replay.Steps {
+		return nil, nil, apierr.NewAPIError(ReplayStepOutOfRange, http.StatusBadRequest)
+	}
+	for replay.Step < step {
+		replay.Next()
+	}
+
+	return game, replay, nil
+}
+
+func (g *gameUsecase) findPreset(name string) (model.Preset, *apierr.ApiError) {
+	if preset, exists := model.BuiltinPreset(name); exists {
+		return preset, nil
+	}
+
+	preset, err := g.presets.FindByName(name)
+	if err != nil {
+		if err.Status == http.StatusNotFound {
+			return model.Preset{}, apierr.NewAPIError(UnknownPreset, http.StatusBadRequest)
+		}
+		return model.Preset{}, err
+	}
+
+	return *preset, nil
 }
 
 func (g *gameUsecase) FindAll() ([]*model.Game, *apierr.ApiError) {
-	return g.repo.FindAll()
+	games, err := g.repo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, game := range games {
+		err = g.expire(game, time.Now())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return games, nil
+}
+
+func (g *gameUsecase) FindByID(id string) (*model.Game, *apierr.ApiError) {
+	game, err := g.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = g.expire(game, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return game, nil
+}
+
+// ExpireGames ends every game that ran out of time or was left idle, even
+// if no one asks for it again. A game that fails to end doesn't stop the
+// others, the errors are returned by game ID.
+func (g *gameUsecase) ExpireGames() (map[string]*apierr.ApiError, *apierr.ApiError) {
+	games, err := g.repo.FindUnfinished()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	failed := map[string]*apierr.ApiError{}
+	for _, game := range games {
+		err := g.expire(game, now)
+		if err != nil {
+			failed[game.ID] = err
+		}
+	}
+
+	return failed, nil
 }
 
-func (g *gameUsecase) FindByID(id int) (*model.Game, *apierr.ApiError) {
-	return g.repo.FindByID(id)
+// expire ends the game when it ran out of time or went idle. It is ended
+// holding the game lock, so reads and moves racing on an expired game
+// don't conflict.
+func (g *gameUsecase) expire(game *model.Game, now time.Time) *apierr.ApiError {
+	if _, expired := g.expiry(game, now); !expired {
+		return nil
+	}
+
+	expired, err := g.repo.Update(game.ID, func(game *model.Game) ([]model.Event, *apierr.ApiError) {
+		// The game may have been played, or ended, since it was read
+		reason, expired := g.expiry(game, now)
+		if !expired {
+			return nil, nil
+		}
+		return play(game, nil, model.NewFinishedEvent(reason, model.ServerActor, now)), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	*game = *expired
+	return nil
+}
+
+// expiry tells why the game must end on its own, if it must.
+func (g *gameUsecase) expiry(game *model.Game, now time.Time) (model.FinishReason, bool) {
+	switch {
+	case game.Expired(now):
+		return model.TimeExpired, true
+	case game.Idle(now, g.idleTimeout):
+		return model.IdleTimeout, true
+	default:
+		return "", false
+	}
+}
+
+func (g *gameUsecase) Reveal(ID string, row, col int) (*model.Game, *apierr.ApiError) {
+	return g.update(ID, func(game *model.Game, now time.Time) ([]model.Event, *apierr.ApiError) {
+		return g.reveal(game, nil, row, col, now)
+	})
 }
 
-func (g *gameUsecase) Reveal(ID, row, col int) (*model.Game, *apierr.ApiError) {
+func (g *gameUsecase) Chord(ID string, row, col int) (*model.Game, *apierr.ApiError) {
+	return g.update(ID, func(game *model.Game, now time.Time) ([]model.Event, *apierr.ApiError) {
+		apiError := validateMove(game, row, col)
+		if apiError != nil {
+			return nil, apiError
+		}
+
+		if !game.Grid[row][col].Revealed {
+			return nil, apierr.NewAPIError(CantChordAHiddenCell, http.StatusBadRequest)
+		}
+
+		flags := 0
+		for _, n := range game.Neighbours(row, col) {
+			if game.Grid[n.Row][n.Col].Flagged() {
+				flags++
+			}
+		}
+		if flags != int(game.Grid[row][col].MinesAround) {
+			return nil, apierr.NewAPIError(FlagsAroundDontMatchMinesAround, http.StatusBadRequest)
+		}
+
+		events := play(game, nil, model.NewCellEvent(model.ChordedEvent, row, col, now))
+		return finish(game, events, mineAround(game, row, col), now), nil
+	})
+}
+
+func (g *gameUsecase) Flag(ID string, row, col int) (*model.Game, *apierr.ApiError) {
+	return g.update(ID, func(game *model.Game, now time.Time) ([]model.Event, *apierr.ApiError) {
+		apiError := validateCellUpdate(game, row, col)
+		if apiError != nil {
+			return nil, apiError
+		}
+
+		event := model.NewCellEvent(model.FlaggedEvent, row, col, now)
+		event.Mark = model.NextMark(game.MarkCycle, game.Grid[row][col].Mark)
+		return play(game, nil, event), nil
+	})
+}
+
+func (g *gameUsecase) Pause(ID string) (*model.Game, *apierr.ApiError) {
 	game, err := g.FindByID(ID)
 	if err != nil {
 		return nil, err
 	}
 
-	apiError := validateCellUpdate(game, row, col)
-	if apiError != nil {
-		return nil, apiError
+	if game.Status != model.Running {
+		return nil, apierr.NewAPIError(OnlyRunningGamesCanBePaused, http.StatusBadRequest)
 	}
 
-	if game.Grid[row][col].Flagged {
-		return nil, apierr.NewAPIError(CantRevealAFlaggedCell, http.StatusBadRequest)
+	if game.TimeLimit > 0 {
+		return nil, apierr.NewAPIError(TimedGamesCantBePaused, http.StatusBadRequest)
 	}
 
-	game.Grid[row][col].Revealed = true
-	game.CellsRevealed++
+	err = g.repo.Append(game, play(game, nil, model.Event{Kind: model.PausedEvent, Time: time.Now()})...)
 
-	if loose(game, row, col) {
-		game.Status = model.Loose
-		game.FinishTime = time.Now()
-		return game, nil
+	if err != nil {
+		return nil, err
 	}
 
-	if game.Grid[row][col].MinesAround == 0 {
-		revealAdjacentSquares(game, row, col)
+	return game, nil
+}
+
+func (g *gameUsecase) Resume(ID string) (*model.Game, *apierr.ApiError) {
+	game, err := g.FindByID(ID)
+	if err != nil {
+		return nil, err
 	}
 
-	if win(game) {
-		game.Status = model.Win
-		game.FinishTime = time.Now()
+	if game.Status != model.Paused {
+		return nil, apierr.NewAPIError(OnlyPausedGamesCanBeResumed, http.StatusBadRequest)
 	}
 
-	err = g.repo.Upsert(game)
+	err = g.repo.Append(game, play(game, nil, model.Event{Kind: model.ResumedEvent, Time: time.Now()})...)
 
 	if err != nil {
 		return nil, err
@@ -94,55 +385,248 @@ func (g *gameUsecase) Reveal(ID, row, col int) (*model.Game, *apierr.ApiError) {
 	return game, nil
 }
 
-func (g *gameUsecase) Flag(ID, row, col int) (*model.Game, *apierr.ApiError) {
+func (g *gameUsecase) Resign(ID string) (*model.Game, *apierr.ApiError) {
 	game, err := g.FindByID(ID)
 	if err != nil {
 		return nil, err
 	}
 
-	apiError := validateCellUpdate(game, row, col)
+	if game.Status.Finished() {
+		return nil, apierr.NewAPIError(OnlyUnfinishedGamesCanBeResigned, http.StatusBadRequest)
+	}
+
+	event := model.NewFinishedEvent(model.PlayerResigned, model.PlayerActor, time.Now())
+
+	err = g.repo.Append(game, play(game, nil, event)...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return game, nil
+}
+
+// Undo rolls back the last moves of a game, playing the ones left again
+// on a hidden board. Only practice games can undo the reveal that lost
+// them. Every undone move is counted on the game.
+func (g *gameUsecase) Undo(ID string, moves int) (*model.Game, *apierr.ApiError) {
+	game, err := g.FindByID(ID)
+	if err != nil {
+		return nil, err
+	}
+
+	apiError := validateUndo(game, moves)
 	if apiError != nil {
 		return nil, apiError
 	}
 
-	if game.Grid[row][col].Flagged {
-		game.Grid[row][col].Flagged = false
-	} else {
-		game.Grid[row][col].Flagged = true
-	}
+	event := model.Event{Kind: model.UndoneEvent, Time: time.Now(), Moves: moves}
+
+	err = g.repo.Append(game, play(game, nil, event)...)
 
-	g.repo.Upsert(game)
+	if err != nil {
+		return nil, err
+	}
 
 	return game, nil
 }
 
-func revealAdjacentSquares(game *model.Game, row, col int) {
-	for x := row - 1; x < row+2; x++ {
-		if x < 0 || x > game.Rows-1 {
-			continue
-		}
+// Hint suggests the player a move, deduced from what they can see on the
+// board. Every hint is counted on the game.
+func (g *gameUsecase) Hint(ID string) (*model.Game, solver.Hint, *apierr.ApiError) {
+	game, err := g.FindByID(ID)
+	if err != nil {
+		return nil, solver.Hint{}, err
+	}
+
+	apiError := validateRunning(game)
+	if apiError != nil {
+		return nil, solver.Hint{}, apiError
+	}
+
+	hint, ok := firstRevealHint(game)
+	if !ok {
+		hint, ok = solver.NewSolver(game).Hint()
+	}
+	if !ok {
+		return nil, solver.Hint{}, apierr.NewAPIError(NoHiddenCellToHint, http.StatusBadRequest)
+	}
+
+	event := model.NewCellEvent(model.HintedEvent, hint.Row, hint.Col, time.Now())
+
+	err = g.repo.Append(game, play(game, nil, event)...)
+
+	if err != nil {
+		return nil, solver.Hint{}, err
+	}
+
+	return game, hint, nil
+}
+
+// Probabilities returns the probability of each hidden cell being a mine,
+// given what the player can see on the board. Flagged cells are taken as
+// mines.
+func (g *gameUsecase) Probabilities(ID string) (*model.Game, [][]float64, *apierr.ApiError) {
+	game, err := g.FindByID(ID)
+	if err != nil {
+		return nil, nil, err
+	}
 
-		for y := col - 1; y < col+2; y++ {
-			if y < 0 || y > game.Cols-1 {
+	probabilities, solverErr := solver.NewSolver(game).Probabilities()
+	switch solverErr {
+	case nil:
+		return game, probabilities, nil
+	case solver.ErrTooManyConfigurations:
+		return nil, nil, apierr.NewAPIError(TooManyUndeterminedCells, http.StatusBadRequest)
+	case solver.ErrInconsistentBoard:
+		return nil, nil, apierr.NewAPIError(FlagsDontMatchTheRevealedNumbers, http.StatusBadRequest)
+	default:
+		return nil, nil, apierr.NewAPIError(solverErr.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Autosolve plays every move that can be deduced from what the player
+// can see, flagging the certain mines and revealing the certain safe
+// cells, until no more can be deduced. Flagged cells are taken as mines.
+// It returns the moves it played.
+func (g *gameUsecase) Autosolve(ID string) (*model.Game, []model.Move, *apierr.ApiError) {
+	game, err := g.FindByID(ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	apiError := validateRunning(game)
+	if apiError != nil {
+		return nil, nil, apiError
+	}
+
+	now := time.Now()
+	played := len(game.Moves)
+	var events []model.Event
+	// One solver follows the whole game, told of every move played, so
+	// each step only looks at the numbers still around hidden cells
+	s := solver.NewSolver(game)
+	for game.Status == model.Running {
+		moves := s.Step()
+		if len(moves) == 0 {
+			break
+		}
+		for _, move := range moves {
+			if game.Status != model.Running || game.Grid[move.Row][move.Col].Revealed {
+				// The game ended, or a cascade already revealed the cell
 				continue
 			}
-			if x == row && y == col {
-				continue
+			if move.Mine {
+				events, apiError = mark(game, events, move.Row, move.Col, model.Flag, now)
+			} else {
+				events, apiError = g.reveal(game, events, move.Row, move.Col, now)
 			}
-			if game.Grid[x][y].Revealed {
-				continue
+			if apiError != nil {
+				return nil, nil, apiError
 			}
-			if game.Grid[x][y].Flagged {
-				continue
+			if move.Mine {
+				s.MarkMine(move.Row, move.Col)
+			} else {
+				s.Revealed(move.Row, move.Col)
 			}
+		}
+	}
 
-			game.Grid[x][y].Revealed = true
-			game.CellsRevealed++
-			if game.Grid[row][col].MinesAround == 0 {
-				revealAdjacentSquares(game, row, col)
-			}
+	err = g.repo.Append(game, events...)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return game, game.Moves[played:], nil
+}
+
+// update plays a move on the game holding its lock, so concurrent moves
+// on the same game run one after the other. Games that ran out of time or
+// went idle are ended first, even when the move is then rejected.
+func (g *gameUsecase) update(ID string, move func(game *model.Game, now time.Time) ([]model.Event, *apierr.ApiError)) (*model.Game, *apierr.ApiError) {
+	_, err := g.FindByID(ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.repo.Update(ID, func(game *model.Game) ([]model.Event, *apierr.ApiError) {
+		return move(game, time.Now())
+	})
+}
+
+// reveal plays a reveal on the cell, placing the mines first on first
+// click safe games.
+func (g *gameUsecase) reveal(game *model.Game, events []model.Event, row, col int, now time.Time) ([]model.Event, *apierr.ApiError) {
+	apiError := validateCellUpdate(game, row, col)
+	if apiError != nil {
+		return nil, apiError
+	}
+
+	if game.Grid[row][col].Flagged() {
+		return nil, apierr.NewAPIError(CantRevealAFlaggedCell, http.StatusBadRequest)
+	}
+
+	if game.FirstClickSafe && !game.MinesPlaced {
+		apiError = g.service.PlaceMines(game, row, col)
+		if apiError != nil {
+			return nil, apiError
 		}
+		events = play(game, events, model.NewMinesPlacedEvent(game, now))
+	}
+
+	events = play(game, events, model.NewCellEvent(model.RevealedEvent, row, col, now))
+	return finish(game, events, loose(game, row, col), now), nil
+}
+
+// mark plays a flag move leaving the given mark on the cell, whatever
+// the mark cycle of the game.
+func mark(game *model.Game, events []model.Event, row, col int, mark model.Mark, now time.Time) ([]model.Event, *apierr.ApiError) {
+	apiError := validateCellUpdate(game, row, col)
+	if apiError != nil {
+		return nil, apiError
+	}
+
+	event := model.NewCellEvent(model.FlaggedEvent, row, col, now)
+	event.Mark = mark
+	return play(game, events, event), nil
+}
+
+// firstRevealHint hints the middle cell of first click safe games whose
+// mines are not placed yet, as any reveal is safe there.
+func firstRevealHint(game *model.Game) (solver.Hint, bool) {
+	if !game.FirstClickSafe || game.MinesPlaced {
+		return solver.Hint{}, false
 	}
+
+	return solver.Hint{
+		Move: solver.Move{
+			Row:         game.Rows / 2,
+			Col:         game.Cols / 2,
+			Explanation: "the first reveal is always safe",
+		},
+		Certain: true,
+	}, true
+}
+
+// play applies an event to the game, keeping it to be stored along with
+// the ones before.
+func play(game *model.Game, events []model.Event, event model.Event) []model.Event {
+	game.Apply(event)
+	return append(events, event)
+}
+
+// finish ends the game when a mine was revealed or every empty cell is.
+func finish(game *model.Game, events []model.Event, lost bool, now time.Time) []model.Event {
+	if lost {
+		return play(game, events, model.NewFinishedEvent(model.MineRevealed, model.PlayerActor, now))
+	}
+
+	if win(game) {
+		return play(game, events, model.NewFinishedEvent(model.BoardCleared, model.PlayerActor, now))
+	}
+
+	return events
 }
 
 func win(game *model.Game) bool {
@@ -153,11 +637,60 @@ func loose(game *model.Game, row, col int) bool {
 	return game.Grid[row][col].Mine
 }
 
+// mineAround tells if a chord on the cell revealed a mine.
+func mineAround(game *model.Game, row, col int) bool {
+	for _, n := range game.Neighbours(row, col) {
+		if loose(game, n.Row, n.Col) && game.Grid[n.Row][n.Col].Revealed {
+			return true
+		}
+	}
+	return false
+}
+
 func validateCellUpdate(game *model.Game, row, col int) *apierr.ApiError {
-	if game.Status != model.Running {
+	apiError := validateMove(game, row, col)
+	if apiError != nil {
+		return apiError
+	}
+
+	if game.Grid[row][col].Revealed {
+		return apierr.NewAPIError(CantUpdateAnAlreadyRevealedCell, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func validateUndo(game *model.Game, moves int) *apierr.ApiError {
+	if game.Status == model.Paused {
+		return apierr.NewAPIError(CantUpdateCellsOnAPausedGame, http.StatusBadRequest)
+	}
+
+	lostOnAMine := game.Status == model.Loose && game.FinishReason == model.MineRevealed
+	if lostOnAMine && !game.Practice {
+		return apierr.NewAPIError(OnlyPracticeGamesCanUndoALoss, http.StatusBadRequest)
+	}
+
+	if game.Status != model.Running && !lostOnAMine {
 		return apierr.NewAPIError(CantUpdateCellsOnAFinishedGame, http.StatusBadRequest)
 	}
 
+	if moves < 1 {
+		return apierr.NewAPIError(MovesToUndoMustBePositive, http.StatusBadRequest)
+	}
+
+	if moves > len(game.Moves) {
+		return apierr.NewAPIError(NotEnoughMovesToUndo, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func validateMove(game *model.Game, row, col int) *apierr.ApiError {
+	apiError := validateRunning(game)
+	if apiError != nil {
+		return apiError
+	}
+
 	if row < 0 || row >= game.Rows {
 		return apierr.NewAPIError(RowValueExceededGridLimits, http.StatusBadRequest)
 	}
@@ -166,8 +699,20 @@ func validateCellUpdate(game *model.Game, row, col int) *apierr.ApiError {
 		return apierr.NewAPIError(ColValueExceededGridLimits, http.StatusBadRequest)
 	}
 
-	if game.Grid[row][col].Revealed {
-		return apierr.NewAPIError(CantUpdateAnAlreadyRevealedCell, http.StatusBadRequest)
+	return nil
+}
+
+func validateRunning(game *model.Game) *apierr.ApiError {
+	if game.Status == model.Paused {
+		return apierr.NewAPIError(CantUpdateCellsOnAPausedGame, http.StatusBadRequest)
+	}
+
+	if game.FinishReason == model.TimeExpired {
+		return apierr.NewAPIError(GameTimeLimitExceeded, http.StatusBadRequest)
+	}
+
+	if game.Status != model.Running {
+		return apierr.NewAPIError(CantUpdateCellsOnAFinishedGame, http.StatusBadRequest)
 	}
 
 	return nil
@@ -1,62 +1,199 @@
 package usecase
 
 import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/egorkos/minesweeper/app/domain/model"
 	"github.com/egorkos/minesweeper/app/domain/repository"
 	"github.com/egorkos/minesweeper/app/domain/service"
+	"github.com/egorkos/minesweeper/app/domain/solver"
 	"github.com/egorkos/minesweeper/app/interface/apierr"
+	"github.com/egorkos/minesweeper/app/interface/persistence/ids"
+	"github.com/egorkos/minesweeper/app/interface/persistence/memory"
+	"github.com/egorkos/minesweeper/app/interface/view"
 	"github.com/stretchr/testify/assert"
 )
 
 type mockGameRepository struct {
-	mockFindAll  func() ([]*model.Game, *apierr.ApiError)
-	mockFindByID func(id int) (*model.Game, *apierr.ApiError)
-	mockUpsert   func(*model.Game) *apierr.ApiError
+	mockFindAll        func() ([]*model.Game, *apierr.ApiError)
+	mockFindUnfinished func() ([]*model.Game, *apierr.ApiError)
+	mockFindByID       func(id string) (*model.Game, *apierr.ApiError)
+	mockFindEvents     func(id string) ([]model.Event, *apierr.ApiError)
+	mockAppend         func(*model.Game, ...model.Event) *apierr.ApiError
 }
 
 func (m mockGameRepository) FindAll() ([]*model.Game, *apierr.ApiError) {
 	return m.mockFindAll()
 }
 
-func (m mockGameRepository) FindByID(id int) (*model.Game, *apierr.ApiError) {
+func (m mockGameRepository) FindUnfinished() ([]*model.Game, *apierr.ApiError) {
+	return m.mockFindUnfinished()
+}
+
+func (m mockGameRepository) FindByID(id string) (*model.Game, *apierr.ApiError) {
 	return m.mockFindByID(id)
 }
 
-func (m mockGameRepository) Upsert(game *model.Game) *apierr.ApiError {
-	return m.mockUpsert(game)
+func (m mockGameRepository) FindEvents(id string) ([]model.Event, *apierr.ApiError) {
+	return m.mockFindEvents(id)
+}
+
+func (m mockGameRepository) Append(game *model.Game, events ...model.Event) *apierr.ApiError {
+	return m.mockAppend(game, events...)
+}
+
+func (m mockGameRepository) Update(id string, update func(*model.Game) ([]model.Event, *apierr.ApiError)) (*model.Game, *apierr.ApiError) {
+	game, err := m.mockFindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := update(game)
+	if err != nil {
+		return nil, err
+	}
+
+	err = m.mockAppend(game, events...)
+	if err != nil {
+		return nil, err
+	}
+
+	return game, nil
+}
+
+type mockPresetRepository struct {
+	mockFindAll    func() ([]*model.Preset, *apierr.ApiError)
+	mockFindByName func(name string) (*model.Preset, *apierr.ApiError)
+	mockUpsert     func(*model.Preset) *apierr.ApiError
+}
+
+func (m mockPresetRepository) FindAll() ([]*model.Preset, *apierr.ApiError) {
+	return m.mockFindAll()
+}
+
+func (m mockPresetRepository) FindByName(name string) (*model.Preset, *apierr.ApiError) {
+	return m.mockFindByName(name)
+}
+
+func (m mockPresetRepository) Upsert(preset *model.Preset) *apierr.ApiError {
+	return m.mockUpsert(preset)
+}
+
+func TestGameUsecaseStartGame(t *testing.T) {
+	presets := &mockPresetRepository{
+		mockFindByName: func(name string) (*model.Preset, *apierr.ApiError) {
+			if name == "tiny" {
+				return &model.Preset{Name: "tiny", Rows: 5, Cols: 4, Mines: 3}, nil
+			}
+			return nil, apierr.NewAPIError("Preset Not Found", http.StatusNotFound)
+		},
+	}
+	repo := &mockGameRepository{
+		mockAppend: func(game *model.Game, events ...model.Event) *apierr.ApiError {
+			return nil
+		},
+	}
+
+	cases := []struct {
+		name     string
+		game     model.Game
+		errText  string
+		expRows  int
+		expCols  int
+		expMines int
+	}{
+		{
+			name:     "OK/DIMENSIONS",
+			game:     model.Game{Rows: 3, Cols: 3, Mines: 1},
+			expRows:  3,
+			expCols:  3,
+			expMines: 1,
+		},
+		{
+			name:     "OK/BUILTIN_PRESET",
+			game:     model.Game{Preset: "expert"},
+			expRows:  16,
+			expCols:  30,
+			expMines: 99,
+		},
+		{
+			name:     "OK/TEMPLATE_PRESET",
+			game:     model.Game{Preset: "tiny"},
+			expRows:  5,
+			expCols:  4,
+			expMines: 3,
+		},
+		{
+			name:    "FAIL/PRESET_ON_WRAPPED_HEX",
+			game:    model.Game{Preset: "beginner", Topology: model.Hex, Wrap: true},
+			errText: "rows: must be even on a wrapped hex board.",
+		},
+		{
+			name:    "FAIL/UNKNOWN_PRESET",
+			game:    model.Game{Preset: "missing"},
+			errText: UnknownPreset,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			gameUsecase := NewGameUsecase(repo, presets, service.NewGameService(repo), 0)
+
+			newGame, err := gameUsecase.StartGame(c.game)
+			if c.errText != "" {
+				assert.Equal(t, c.errText, err.Error())
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, c.game.Preset, newGame.Preset)
+			assert.Equal(t, c.expRows, newGame.Rows)
+			assert.Equal(t, c.expCols, newGame.Cols)
+			assert.Equal(t, c.expMines, newGame.Mines)
+		})
+	}
 }
 
 func TestGameUsecaseReveal(t *testing.T) {
 	minedCell := model.Cell{
 		Mine:        true,
 		Revealed:    false,
-		Flagged:     false,
+		Mark:        model.NoMark,
 		MinesAround: 0,
 	}
 	emptyCell := model.Cell{
 		Mine:        false,
 		Revealed:    false,
-		Flagged:     false,
+		Mark:        model.NoMark,
 		MinesAround: 0,
 	}
 	revealedCell := model.Cell{
 		Mine:        false,
 		Revealed:    true,
-		Flagged:     false,
+		Mark:        model.NoMark,
 		MinesAround: 0,
 	}
 	flaggedCell := model.Cell{
 		Mine:        false,
 		Revealed:    false,
-		Flagged:     true,
+		Mark:        model.Flag,
 		MinesAround: 0,
 	}
+	questionedCell := model.Cell{
+		Mine:        false,
+		Revealed:    false,
+		Mark:        model.Question,
+		MinesAround: 1,
+	}
 
 	cases := []struct {
 		name       string
-		ID         int
+		ID         string
 		repository repository.GameRepository
 		row        int
 		col        int
@@ -66,9 +203,9 @@ func TestGameUsecaseReveal(t *testing.T) {
 	}{
 		{
 			name: "FAIL/FINISHED_GAME",
-			ID:   1,
+			ID:   "1",
 			repository: &mockGameRepository{
-				mockFindByID: func(ID int) (*model.Game, *apierr.ApiError) {
+				mockFindByID: func(ID string) (*model.Game, *apierr.ApiError) {
 					game := model.Game{
 						Rows:  1,
 						Cols:  3,
@@ -87,9 +224,9 @@ func TestGameUsecaseReveal(t *testing.T) {
 		},
 		{
 			name: "FAIL/EXCEED_ROW",
-			ID:   1,
+			ID:   "1",
 			repository: &mockGameRepository{
-				mockFindByID: func(ID int) (*model.Game, *apierr.ApiError) {
+				mockFindByID: func(ID string) (*model.Game, *apierr.ApiError) {
 					game := model.Game{
 						Rows:  1,
 						Cols:  3,
@@ -108,9 +245,9 @@ func TestGameUsecaseReveal(t *testing.T) {
 		},
 		{
 			name: "FAIL/EXCEED_COL",
-			ID:   1,
+			ID:   "1",
 			repository: &mockGameRepository{
-				mockFindByID: func(ID int) (*model.Game, *apierr.ApiError) {
+				mockFindByID: func(ID string) (*model.Game, *apierr.ApiError) {
 					game := model.Game{
 						Rows:  1,
 						Cols:  3,
@@ -129,9 +266,9 @@ func TestGameUsecaseReveal(t *testing.T) {
 		},
 		{
 			name: "FAIL/ALREADY_REVEALED_CELL",
-			ID:   1,
+			ID:   "1",
 			repository: &mockGameRepository{
-				mockFindByID: func(ID int) (*model.Game, *apierr.ApiError) {
+				mockFindByID: func(ID string) (*model.Game, *apierr.ApiError) {
 					game := model.Game{
 						Rows:  1,
 						Cols:  3,
@@ -150,9 +287,9 @@ func TestGameUsecaseReveal(t *testing.T) {
 		},
 		{
 			name: "FAIL/FLAGGED_CELL",
-			ID:   1,
+			ID:   "1",
 			repository: &mockGameRepository{
-				mockFindByID: func(ID int) (*model.Game, *apierr.ApiError) {
+				mockFindByID: func(ID string) (*model.Game, *apierr.ApiError) {
 					game := model.Game{
 						Rows:  1,
 						Cols:  3,
@@ -169,14 +306,44 @@ func TestGameUsecaseReveal(t *testing.T) {
 			col:     0,
 			errText: CantRevealAFlaggedCell,
 		},
+		{
+			name: "OK/REVEAL/QUESTIONED_CELL",
+			ID:   "1",
+			repository: &mockGameRepository{
+				mockAppend: func(game *model.Game, events ...model.Event) *apierr.ApiError {
+					return nil
+				},
+				mockFindByID: func(ID string) (*model.Game, *apierr.ApiError) {
+					game := model.Game{
+						Rows:  1,
+						Cols:  3,
+						Mines: 1,
+						Grid: [][]model.Cell{
+							{emptyCell, questionedCell, minedCell},
+						},
+						Status: model.Running,
+					}
+					return &game, nil
+				},
+			},
+			row:       0,
+			col:       1,
+			errText:   "",
+			expStatus: model.Running,
+			expGame: model.Game{
+				Grid: [][]model.Cell{
+					{emptyCell, revealedCell, minedCell},
+				},
+			},
+		},
 		{
 			name: "OK/REVEAL/MINED_CELL",
-			ID:   1,
+			ID:   "1",
 			repository: &mockGameRepository{
-				mockUpsert: func(game *model.Game) *apierr.ApiError {
+				mockAppend: func(game *model.Game, events ...model.Event) *apierr.ApiError {
 					return nil
 				},
-				mockFindByID: func(ID int) (*model.Game, *apierr.ApiError) {
+				mockFindByID: func(ID string) (*model.Game, *apierr.ApiError) {
 					game := model.Game{
 						Rows:  1,
 						Cols:  3,
@@ -196,12 +363,12 @@ func TestGameUsecaseReveal(t *testing.T) {
 		},
 		{
 			name: "OK/REVEAL/WIN_GAME",
-			ID:   1,
+			ID:   "1",
 			repository: &mockGameRepository{
-				mockUpsert: func(game *model.Game) *apierr.ApiError {
+				mockAppend: func(game *model.Game, events ...model.Event) *apierr.ApiError {
 					return nil
 				},
-				mockFindByID: func(ID int) (*model.Game, *apierr.ApiError) {
+				mockFindByID: func(ID string) (*model.Game, *apierr.ApiError) {
 					game := model.Game{
 						Rows:  1,
 						Cols:  3,
@@ -210,19 +377,19 @@ func TestGameUsecaseReveal(t *testing.T) {
 							{model.Cell{
 								Mine:        true,
 								Revealed:    false,
-								Flagged:     false,
+								Mark:        model.NoMark,
 								MinesAround: 0,
 							},
 								model.Cell{
 									Mine:        false,
 									Revealed:    false,
-									Flagged:     false,
+									Mark:        model.NoMark,
 									MinesAround: 1,
 								},
 								model.Cell{
 									Mine:        false,
 									Revealed:    false,
-									Flagged:     false,
+									Mark:        model.NoMark,
 									MinesAround: 0,
 								}},
 						},
@@ -236,14 +403,94 @@ func TestGameUsecaseReveal(t *testing.T) {
 			errText:   "",
 			expStatus: model.Win,
 		},
+		{
+			name: "OK/REVEAL/FIRST_CLICK_SAFE",
+			ID:   "1",
+			repository: &mockGameRepository{
+				mockAppend: func(game *model.Game, events ...model.Event) *apierr.ApiError {
+					return nil
+				},
+				mockFindByID: func(ID string) (*model.Game, *apierr.ApiError) {
+					game := model.Game{
+						Rows:           3,
+						Cols:           3,
+						Mines:          8,
+						Seed:           1,
+						FirstClickSafe: true,
+						Grid: [][]model.Cell{
+							{emptyCell, emptyCell, emptyCell},
+							{emptyCell, emptyCell, emptyCell},
+							{emptyCell, emptyCell, emptyCell},
+						},
+						Status: model.Running,
+					}
+					return &game, nil
+				},
+			},
+			row:       1,
+			col:       1,
+			errText:   "",
+			expStatus: model.Win,
+		},
+		{
+			name: "OK/REVEAL/WRAP",
+			ID:   "1",
+			repository: &mockGameRepository{
+				mockAppend: func(game *model.Game, events ...model.Event) *apierr.ApiError {
+					return nil
+				},
+				mockFindByID: func(ID string) (*model.Game, *apierr.ApiError) {
+					game := model.Game{
+						Rows:  1,
+						Cols:  4,
+						Mines: 1,
+						Wrap:  true,
+						Grid: [][]model.Cell{
+							{emptyCell, model.Cell{MinesAround: 1}, minedCell, model.Cell{MinesAround: 1}},
+						},
+						Status: model.Running,
+					}
+					return &game, nil
+				},
+			},
+			row:       0,
+			col:       0,
+			errText:   "",
+			expStatus: model.Win,
+		},
+		{
+			name: "OK/REVEAL/CASCADE_BEYOND_NEIGHBOURS",
+			ID:   "1",
+			repository: &mockGameRepository{
+				mockAppend: func(game *model.Game, events ...model.Event) *apierr.ApiError {
+					return nil
+				},
+				mockFindByID: func(ID string) (*model.Game, *apierr.ApiError) {
+					game := model.Game{
+						Rows:  1,
+						Cols:  5,
+						Mines: 1,
+						Grid: [][]model.Cell{
+							{emptyCell, emptyCell, emptyCell, model.Cell{MinesAround: 1}, minedCell},
+						},
+						Status: model.Running,
+					}
+					return &game, nil
+				},
+			},
+			row:       0,
+			col:       0,
+			errText:   "",
+			expStatus: model.Win,
+		},
 		{
 			name: "OK/REVEAL_ADJACENT_SQUARES",
-			ID:   1,
+			ID:   "1",
 			repository: &mockGameRepository{
-				mockUpsert: func(game *model.Game) *apierr.ApiError {
+				mockAppend: func(game *model.Game, events ...model.Event) *apierr.ApiError {
 					return nil
 				},
-				mockFindByID: func(ID int) (*model.Game, *apierr.ApiError) {
+				mockFindByID: func(ID string) (*model.Game, *apierr.ApiError) {
 					game := model.Game{
 						Rows:  3,
 						Cols:  3,
@@ -253,57 +500,57 @@ func TestGameUsecaseReveal(t *testing.T) {
 							{model.Cell{
 								Mine:        false,
 								Revealed:    false,
-								Flagged:     false,
+								Mark:        model.NoMark,
 								MinesAround: 0,
 							},
 								model.Cell{
 									Mine:        false,
 									Revealed:    false,
-									Flagged:     false,
+									Mark:        model.NoMark,
 									MinesAround: 1,
 								},
 								model.Cell{
 									Mine:        false,
 									Revealed:    false,
-									Flagged:     false,
+									Mark:        model.NoMark,
 									MinesAround: 1,
 								}},
 							//2nd row
 							{model.Cell{
 								Mine:        false,
 								Revealed:    false,
-								Flagged:     false,
+								Mark:        model.NoMark,
 								MinesAround: 0,
 							},
 								model.Cell{
 									Mine:        false,
 									Revealed:    false,
-									Flagged:     false,
+									Mark:        model.NoMark,
 									MinesAround: 1,
 								},
 								model.Cell{
 									Mine:        true,
 									Revealed:    false,
-									Flagged:     false,
+									Mark:        model.NoMark,
 									MinesAround: 0,
 								}},
 							//3rd row
 							{model.Cell{
 								Mine:        false,
 								Revealed:    false,
-								Flagged:     false,
+								Mark:        model.NoMark,
 								MinesAround: 0,
 							},
 								model.Cell{
 									Mine:        false,
 									Revealed:    false,
-									Flagged:     false,
+									Mark:        model.NoMark,
 									MinesAround: 1,
 								},
 								model.Cell{
 									Mine:        false,
 									Revealed:    false,
-									Flagged:     false,
+									Mark:        model.NoMark,
 									MinesAround: 1,
 								}},
 						},
@@ -325,57 +572,57 @@ func TestGameUsecaseReveal(t *testing.T) {
 					{model.Cell{
 						Mine:        false,
 						Revealed:    true,
-						Flagged:     false,
+						Mark:        model.NoMark,
 						MinesAround: 0,
 					},
 						model.Cell{
 							Mine:        false,
 							Revealed:    true,
-							Flagged:     false,
+							Mark:        model.NoMark,
 							MinesAround: 1,
 						},
 						model.Cell{
 							Mine:        false,
 							Revealed:    false,
-							Flagged:     false,
+							Mark:        model.NoMark,
 							MinesAround: 1,
 						}},
 					//2nd row
 					{model.Cell{
 						Mine:        false,
 						Revealed:    true,
-						Flagged:     false,
+						Mark:        model.NoMark,
 						MinesAround: 0,
 					},
 						model.Cell{
 							Mine:        false,
 							Revealed:    true,
-							Flagged:     false,
+							Mark:        model.NoMark,
 							MinesAround: 1,
 						},
 						model.Cell{
 							Mine:        true,
 							Revealed:    false,
-							Flagged:     false,
+							Mark:        model.NoMark,
 							MinesAround: 0,
 						}},
 					//3rd row
 					{model.Cell{
 						Mine:        false,
 						Revealed:    true,
-						Flagged:     false,
+						Mark:        model.NoMark,
 						MinesAround: 0,
 					},
 						model.Cell{
 							Mine:        false,
 							Revealed:    true,
-							Flagged:     false,
+							Mark:        model.NoMark,
 							MinesAround: 1,
 						},
 						model.Cell{
 							Mine:        false,
 							Revealed:    false,
-							Flagged:     false,
+							Mark:        model.NoMark,
 							MinesAround: 1,
 						}},
 				},
@@ -411,3 +658,1183 @@ func TestGameUsecaseReveal(t *testing.T) {
 		})
 	}
 }
+
+func TestGameUsecaseChord(t *testing.T) {
+	// 3x4 board with a mine in the top left corner, (0,1) already revealed
+	newGame := func(flagRow, flagCol int) *model.Game {
+		game := model.Game{
+			Rows:  3,
+			Cols:  4,
+			Mines: 1,
+			Grid: [][]model.Cell{
+				{{Mine: true}, {Revealed: true, MinesAround: 1}, {}, {}},
+				{{MinesAround: 1}, {MinesAround: 1}, {}, {}},
+				{{}, {}, {}, {}},
+			},
+			CellsRevealed: 1,
+			Status:        model.Running,
+		}
+		if flagRow >= 0 {
+			game.Grid[flagRow][flagCol].Mark = model.Flag
+		}
+		return &game
+	}
+
+	cases := []struct {
+		name        string
+		game        *model.Game
+		row         int
+		col         int
+		errText     string
+		expStatus   model.GameStatus
+		expRevealed []model.Coordinate
+	}{
+		{
+			name:    "FAIL/HIDDEN_CELL",
+			game:    newGame(0, 0),
+			row:     1,
+			col:     1,
+			errText: CantChordAHiddenCell,
+		},
+		{
+			name:    "FAIL/FLAGS_MISMATCH",
+			game:    newGame(-1, -1),
+			row:     0,
+			col:     1,
+			errText: FlagsAroundDontMatchMinesAround,
+		},
+		{
+			name:        "OK/CASCADE",
+			game:        newGame(0, 0),
+			row:         0,
+			col:         1,
+			expStatus:   model.Win,
+			expRevealed: []model.Coordinate{{Row: 0, Col: 2}, {Row: 0, Col: 3}, {Row: 1, Col: 0}, {Row: 2, Col: 0}, {Row: 2, Col: 3}},
+		},
+		{
+			name:      "OK/WRONG_FLAG",
+			game:      newGame(1, 0),
+			row:       0,
+			col:       1,
+			expStatus: model.Loose,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			game := c.game
+			repo := &mockGameRepository{
+				mockAppend: func(game *model.Game, events ...model.Event) *apierr.ApiError {
+					return nil
+				},
+				mockFindByID: func(ID string) (*model.Game, *apierr.ApiError) {
+					return game, nil
+				},
+			}
+			gameUsecase := gameUsecase{
+				service: service.NewGameService(repo),
+				repo:    repo,
+			}
+
+			upsertedGame, err := gameUsecase.Chord("1", c.row, c.col)
+			if c.errText != "" {
+				assert.Equal(t, c.errText, err.Error())
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, c.expStatus, upsertedGame.Status)
+			for _, cell := range c.expRevealed {
+				assert.True(t, upsertedGame.Grid[cell.Row][cell.Col].Revealed)
+			}
+			if c.expStatus != model.Loose {
+				assert.False(t, upsertedGame.Grid[0][0].Revealed)
+			}
+		})
+	}
+}
+
+func TestGameUsecaseFlag(t *testing.T) {
+	cases := []struct {
+		name          string
+		markCycle     []model.Mark
+		marks         []model.Mark
+		row           int
+		expectedError string
+	}{
+		{
+			name:  "OK/DEFAULT_CYCLE",
+			marks: []model.Mark{model.Flag, model.NoMark, model.Flag},
+		},
+		{
+			name:      "OK/QUESTION_CYCLE",
+			markCycle: []model.Mark{model.NoMark, model.Flag, model.Question},
+			marks:     []model.Mark{model.Flag, model.Question, model.NoMark},
+		},
+		{
+			name:          "FAIL/ROW_OUT_OF_GRID",
+			row:           1,
+			expectedError: RowValueExceededGridLimits,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			game := &model.Game{
+				Rows:      1,
+				Cols:      2,
+				Mines:     1,
+				MarkCycle: c.markCycle,
+				Grid: [][]model.Cell{
+					{{Mine: true}, {MinesAround: 1}},
+				},
+				Status: model.Running,
+			}
+			repo := &mockGameRepository{
+				mockAppend: func(game *model.Game, events ...model.Event) *apierr.ApiError {
+					return nil
+				},
+				mockFindByID: func(ID string) (*model.Game, *apierr.ApiError) {
+					return game, nil
+				},
+			}
+			gameUsecase := gameUsecase{
+				service: service.NewGameService(repo),
+				repo:    repo,
+			}
+
+			if c.expectedError != "" {
+				_, err := gameUsecase.Flag("1", c.row, 0)
+				assert.Equal(t, c.expectedError, err.Error())
+				return
+			}
+
+			for _, mark := range c.marks {
+				upsertedGame, err := gameUsecase.Flag("1", 0, 0)
+				assert.Nil(t, err)
+				assert.Equal(t, mark, upsertedGame.Grid[0][0].Mark)
+			}
+		})
+	}
+}
+
+// BenchmarkGameUsecaseReveal reveals the corner of a board with a single
+// mine in the opposite one, so the flood fill opens the whole board.
+func BenchmarkGameUsecaseReveal(b *testing.B) {
+	for _, size := range []int{50, 250, 1000} {
+		b.Run(fmt.Sprintf("%dx%d", size, size), func(b *testing.B) {
+			var game model.Game
+			repo := &mockGameRepository{
+				mockAppend: func(game *model.Game, events ...model.Event) *apierr.ApiError {
+					return nil
+				},
+				mockFindByID: func(ID string) (*model.Game, *apierr.ApiError) {
+					return &game, nil
+				},
+			}
+			gameUsecase := gameUsecase{
+				service: service.NewGameService(repo),
+				repo:    repo,
+			}
+
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				game = model.Game{
+					Rows:   size,
+					Cols:   size,
+					Mines:  1,
+					Grid:   model.NewGrid(size, size),
+					Status: model.Running,
+				}
+				game.Grid[0][0].Mine = true
+				for _, n := range game.Neighbours(0, 0) {
+					game.Grid[n.Row][n.Col].MinesAround = 1
+				}
+				b.StartTimer()
+
+				upsertedGame, _ := gameUsecase.Reveal("1", size-1, size-1)
+				if upsertedGame.Status != model.Win {
+					b.Fatalf("expected a won game, got %v", upsertedGame.Status)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkGameUsecaseRevealResponse is BenchmarkGameUsecaseReveal through
+// the memory repository, building the response the reveal gets: the game
+// view encoded as JSON.
+func BenchmarkGameUsecaseRevealResponse(b *testing.B) {
+	for _, size := range []int{50, 250, 1000} {
+		b.Run(fmt.Sprintf("%dx%d", size, size), func(b *testing.B) {
+			board := model.Game{
+				Rows:        size,
+				Cols:        size,
+				Mines:       1,
+				Grid:        model.NewGrid(size, size),
+				MinesPlaced: true,
+			}
+			board.Grid[0][0].Mine = true
+			created := model.NewCreatedEvent(&board, time.Now())
+
+			b.ReportAllocs()
+			var response []byte
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				repo := memory.NewGameRepository(ids.NewSequentialAllocator())
+				var game model.Game
+				game.Apply(created)
+				if err := repo.Append(&game, created); err != nil {
+					b.Fatal(err)
+				}
+				gameUsecase := gameUsecase{
+					service: service.NewGameService(repo),
+					repo:    repo,
+				}
+				b.StartTimer()
+
+				upsertedGame, apiErr := gameUsecase.Reveal(game.ID, size-1, size-1)
+				if apiErr != nil {
+					b.Fatal(apiErr)
+				}
+				data, err := json.Marshal(view.NewGameView(upsertedGame))
+				if err != nil {
+					b.Fatal(err)
+				}
+				response = data
+			}
+			b.ReportMetric(float64(len(response)), "response-B")
+		})
+	}
+}
+
+func TestGameUsecasePauseResume(t *testing.T) {
+	game := &model.Game{
+		Rows:  1,
+		Cols:  2,
+		Mines: 1,
+		Grid: [][]model.Cell{
+			{{Mine: true}, {MinesAround: 1}},
+		},
+		ResumeTime: time.Now().Add(-time.Minute),
+		Status:     model.Running,
+	}
+	repo := &mockGameRepository{
+		mockAppend: func(game *model.Game, events ...model.Event) *apierr.ApiError {
+			return nil
+		},
+		mockFindByID: func(ID string) (*model.Game, *apierr.ApiError) {
+			return game, nil
+		},
+	}
+	gameUsecase := gameUsecase{
+		service: service.NewGameService(repo),
+		repo:    repo,
+	}
+
+	_, err := gameUsecase.Resume("1")
+	assert.Equal(t, OnlyPausedGamesCanBeResumed, err.Error())
+
+	pausedGame, err := gameUsecase.Pause("1")
+	assert.Nil(t, err)
+	assert.Equal(t, model.Paused, pausedGame.Status)
+	assert.True(t, pausedGame.ActiveTime >= time.Minute)
+	activeTime := pausedGame.ActiveTime
+
+	_, err = gameUsecase.Pause("1")
+	assert.Equal(t, OnlyRunningGamesCanBePaused, err.Error())
+
+	_, err = gameUsecase.Reveal("1", 0, 1)
+	assert.Equal(t, CantUpdateCellsOnAPausedGame, err.Error())
+
+	_, err = gameUsecase.Flag("1", 0, 0)
+	assert.Equal(t, CantUpdateCellsOnAPausedGame, err.Error())
+
+	resumedGame, err := gameUsecase.Resume("1")
+	assert.Nil(t, err)
+	assert.Equal(t, model.Running, resumedGame.Status)
+
+	wonGame, err := gameUsecase.Reveal("1", 0, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, model.Win, wonGame.Status)
+	assert.True(t, wonGame.ActiveTime >= activeTime)
+	assert.True(t, wonGame.ActiveTime < activeTime+time.Minute)
+}
+
+func TestGameUsecaseTimeLimit(t *testing.T) {
+	newGame := func() *model.Game {
+		return &model.Game{
+			Rows:  1,
+			Cols:  2,
+			Mines: 1,
+			Grid: [][]model.Cell{
+				{{Mine: true}, {MinesAround: 1}},
+			},
+			ResumeTime: time.Now().Add(-time.Minute),
+			TimeLimit:  30,
+			Status:     model.Running,
+		}
+	}
+
+	t.Run("FAIL/REVEAL_AFTER_TIME_LIMIT", func(t *testing.T) {
+		game := newGame()
+		var upsertedGame *model.Game
+		repo := &mockGameRepository{
+			mockAppend: func(game *model.Game, events ...model.Event) *apierr.ApiError {
+				upsertedGame = game
+				return nil
+			},
+			mockFindByID: func(ID string) (*model.Game, *apierr.ApiError) {
+				return game, nil
+			},
+		}
+		gameUsecase := gameUsecase{
+			service: service.NewGameService(repo),
+			repo:    repo,
+		}
+
+		_, err := gameUsecase.Reveal("1", 0, 1)
+		assert.Equal(t, GameTimeLimitExceeded, err.Error())
+		assert.Equal(t, model.Loose, upsertedGame.Status)
+		assert.Equal(t, model.TimeExpired, upsertedGame.FinishReason)
+		assert.False(t, upsertedGame.Grid[0][1].Revealed)
+	})
+
+	t.Run("FAIL/PAUSE", func(t *testing.T) {
+		game := newGame()
+		game.ResumeTime = time.Now()
+		repo := &mockGameRepository{
+			mockFindByID: func(ID string) (*model.Game, *apierr.ApiError) {
+				return game, nil
+			},
+		}
+		gameUsecase := gameUsecase{
+			service: service.NewGameService(repo),
+			repo:    repo,
+		}
+
+		_, err := gameUsecase.Pause("1")
+		assert.Equal(t, TimedGamesCantBePaused, err.Error())
+	})
+
+	t.Run("OK/EXPIRE_GAMES", func(t *testing.T) {
+		expired := newGame()
+		expired.ID = "1"
+		running := newGame()
+		running.ID = "2"
+		running.ResumeTime = time.Now()
+		games := map[string]*model.Game{"1": expired, "2": running}
+		upserts := 0
+		repo := &mockGameRepository{
+			mockAppend: func(game *model.Game, events ...model.Event) *apierr.ApiError {
+				upserts++
+				return nil
+			},
+			mockFindUnfinished: func() ([]*model.Game, *apierr.ApiError) {
+				return []*model.Game{expired, running}, nil
+			},
+			mockFindByID: func(ID string) (*model.Game, *apierr.ApiError) {
+				return games[ID], nil
+			},
+		}
+		gameUsecase := gameUsecase{
+			service: service.NewGameService(repo),
+			repo:    repo,
+		}
+
+		failed, err := gameUsecase.ExpireGames()
+		assert.Nil(t, err)
+		assert.Empty(t, failed)
+		assert.Equal(t, 1, upserts)
+		assert.Equal(t, model.Loose, expired.Status)
+		assert.Equal(t, model.TimeExpired, expired.FinishReason)
+		assert.Equal(t, model.ServerActor, expired.EndedBy)
+		assert.Equal(t, 30*time.Second, expired.ActiveTime)
+		assert.Equal(t, model.Running, running.Status)
+	})
+
+	t.Run("OK/EXPIRE_GAMES_AFTER_A_FAILURE", func(t *testing.T) {
+		failing := newGame()
+		failing.ID = "1"
+		expired := newGame()
+		expired.ID = "2"
+		games := map[string]*model.Game{"1": failing, "2": expired}
+		conflict := apierr.NewAPIError("conflict", http.StatusConflict)
+		repo := &mockGameRepository{
+			mockAppend: func(game *model.Game, events ...model.Event) *apierr.ApiError {
+				if game.ID == "1" {
+					return conflict
+				}
+				return nil
+			},
+			mockFindUnfinished: func() ([]*model.Game, *apierr.ApiError) {
+				return []*model.Game{failing, expired}, nil
+			},
+			mockFindByID: func(ID string) (*model.Game, *apierr.ApiError) {
+				return games[ID], nil
+			},
+		}
+		gameUsecase := gameUsecase{
+			service: service.NewGameService(repo),
+			repo:    repo,
+		}
+
+		failed, err := gameUsecase.ExpireGames()
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]*apierr.ApiError{"1": conflict}, failed)
+		assert.Equal(t, model.Loose, expired.Status)
+	})
+
+	t.Run("OK/CONCURRENT_READS_AND_MOVES", func(t *testing.T) {
+		const requests = 5
+		stored := memory.NewGameRepository(ids.NewSequentialAllocator())
+		board := newGame()
+		board.MinesPlaced = true
+		created := model.NewCreatedEvent(board, time.Now().Add(-time.Minute))
+		var game model.Game
+		game.Apply(created)
+		assert.Nil(t, stored.Append(&game, created))
+
+		// Every request reads the game before any of them ends it
+		readers := &sync.WaitGroup{}
+		readers.Add(2 * requests)
+		repo := readBarrier{GameRepository: stored, readers: readers}
+		gameUsecase := gameUsecase{
+			service: service.NewGameService(repo),
+			repo:    repo,
+		}
+
+		var wg sync.WaitGroup
+		for i := 0; i < requests; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				found, err := gameUsecase.FindByID(game.ID)
+				if assert.Nil(t, err) {
+					assert.Equal(t, model.TimeExpired, found.FinishReason)
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				_, err := gameUsecase.Reveal(game.ID, 0, 1)
+				assert.Equal(t, GameTimeLimitExceeded, err.Error())
+			}()
+		}
+		wg.Wait()
+
+		events, err := stored.FindEvents(game.ID)
+		assert.Nil(t, err)
+		assert.Len(t, events, 2)
+		assert.Equal(t, model.FinishedEvent, events[1].Kind)
+	})
+}
+
+// readBarrier holds every read of a game until all the expected readers
+// read it, so they all get the same version.
+type readBarrier struct {
+	repository.GameRepository
+	readers *sync.WaitGroup
+}
+
+func (r readBarrier) FindByID(ID string) (*model.Game, *apierr.ApiError) {
+	game, err := r.GameRepository.FindByID(ID)
+	r.readers.Done()
+	r.readers.Wait()
+	return game, err
+}
+
+func TestGameUsecaseResign(t *testing.T) {
+	cases := []struct {
+		name          string
+		status        model.GameStatus
+		expectedError string
+	}{
+		{
+			name:   "OK/RUNNING",
+			status: model.Running,
+		},
+		{
+			name:   "OK/PAUSED",
+			status: model.Paused,
+		},
+		{
+			name:          "FAIL/WON",
+			status:        model.Win,
+			expectedError: OnlyUnfinishedGamesCanBeResigned,
+		},
+		{
+			name:          "FAIL/ABANDONED",
+			status:        model.Abandoned,
+			expectedError: OnlyUnfinishedGamesCanBeResigned,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			game := &model.Game{
+				Rows:         1,
+				Cols:         2,
+				Mines:        1,
+				LastMoveTime: time.Now(),
+				Status:       c.status,
+			}
+			repo := &mockGameRepository{
+				mockAppend: func(game *model.Game, events ...model.Event) *apierr.ApiError {
+					return nil
+				},
+				mockFindByID: func(ID string) (*model.Game, *apierr.ApiError) {
+					return game, nil
+				},
+			}
+			gameUsecase := gameUsecase{
+				service: service.NewGameService(repo),
+				repo:    repo,
+			}
+
+			resignedGame, err := gameUsecase.Resign("1")
+			if c.expectedError != "" {
+				assert.Equal(t, c.expectedError, err.Error())
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, model.Resigned, resignedGame.Status)
+			assert.Equal(t, model.PlayerResigned, resignedGame.FinishReason)
+			assert.Equal(t, model.PlayerActor, resignedGame.EndedBy)
+		})
+	}
+}
+
+func TestGameUsecaseRestart(t *testing.T) {
+	cases := []struct {
+		name          string
+		status        model.GameStatus
+		reason        model.FinishReason
+		practice      bool
+		expectedError string
+	}{
+		{
+			name:   "OK/WON",
+			status: model.Win,
+		},
+		{
+			name:   "OK/RESIGNED",
+			status: model.Resigned,
+		},
+		{
+			name:   "OK/LOST",
+			status: model.Loose,
+			reason: model.MineRevealed,
+		},
+		{
+			name:          "FAIL/PRACTICE_LOSS",
+			status:        model.Loose,
+			reason:        model.MineRevealed,
+			practice:      true,
+			expectedError: PracticeLossesCantBeRestarted,
+		},
+		{
+			name:          "FAIL/RUNNING",
+			status:        model.Running,
+			expectedError: OnlyFinishedGamesCanBeRestarted,
+		},
+		{
+			name:          "FAIL/PAUSED",
+			status:        model.Paused,
+			expectedError: OnlyFinishedGamesCanBeRestarted,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			source := &model.Game{
+				ID:           "1",
+				Rows:         1,
+				Cols:         2,
+				Mines:        1,
+				MinesPlaced:  true,
+				Grid:         [][]model.Cell{{{Mine: true}, {MinesAround: 1}}},
+				Practice:     c.practice,
+				LastMoveTime: time.Now(),
+				Status:       c.status,
+				FinishReason: c.reason,
+			}
+			var appended []*model.Game
+			repo := &mockGameRepository{
+				mockAppend: func(game *model.Game, events ...model.Event) *apierr.ApiError {
+					appended = append(appended, game)
+					return nil
+				},
+				mockFindByID: func(ID string) (*model.Game, *apierr.ApiError) {
+					return source, nil
+				},
+			}
+			gameUsecase := gameUsecase{
+				service: service.NewGameService(repo),
+				repo:    repo,
+			}
+
+			restartedGame, err := gameUsecase.Restart("1")
+			if c.expectedError != "" {
+				assert.Equal(t, c.expectedError, err.Error())
+				assert.Empty(t, appended)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, model.Running, restartedGame.Status)
+			assert.Equal(t, "1", restartedGame.RestartedFrom)
+			assert.True(t, restartedGame.Grid[0][0].Mine)
+			assert.False(t, restartedGame.Grid[0][0].Revealed)
+		})
+	}
+}
+
+func TestGameUsecaseAbandonIdleGames(t *testing.T) {
+	idle := &model.Game{
+		LastMoveTime: time.Now().Add(-time.Hour),
+		Status:       model.Paused,
+	}
+	active := &model.Game{
+		LastMoveTime: time.Now(),
+		Status:       model.Running,
+	}
+	repo := &mockGameRepository{
+		mockAppend: func(game *model.Game, events ...model.Event) *apierr.ApiError {
+			return nil
+		},
+		mockFindUnfinished: func() ([]*model.Game, *apierr.ApiError) {
+			return []*model.Game{idle, active}, nil
+		},
+		mockFindByID: func(ID string) (*model.Game, *apierr.ApiError) {
+			return idle, nil
+		},
+	}
+	gameUsecase := gameUsecase{
+		service:     service.NewGameService(repo),
+		repo:        repo,
+		idleTimeout: time.Minute,
+	}
+
+	failed, err := gameUsecase.ExpireGames()
+	assert.Nil(t, err)
+	assert.Empty(t, failed)
+	assert.Equal(t, model.Abandoned, idle.Status)
+	assert.Equal(t, model.IdleTimeout, idle.FinishReason)
+	assert.Equal(t, model.ServerActor, idle.EndedBy)
+	assert.Equal(t, model.Running, active.Status)
+}
+
+func TestGameUsecaseUndo(t *testing.T) {
+	cases := []struct {
+		name          string
+		practice      bool
+		play          func(g gameUsecase)
+		moves         int
+		expectedError string
+		expected      func(t *testing.T, game *model.Game)
+	}{
+		{
+			name: "OK/UNDO_REVEAL",
+			play: func(g gameUsecase) {
+				g.Flag("1", 0, 3)
+				g.Reveal("1", 0, 0)
+			},
+			moves: 1,
+			expected: func(t *testing.T, game *model.Game) {
+				assert.Equal(t, model.Running, game.Status)
+				assert.False(t, game.Grid[0][0].Revealed)
+				assert.False(t, game.Grid[0][1].Revealed)
+				assert.Equal(t, model.Flag, game.Grid[0][3].Mark)
+				assert.Equal(t, 0, game.CellsRevealed)
+				assert.Equal(t, 1, len(game.Moves))
+				assert.Equal(t, 1, game.Undos)
+			},
+		},
+		{
+			name: "OK/UNDO_EVERY_MOVE",
+			play: func(g gameUsecase) {
+				g.Flag("1", 0, 3)
+				g.Reveal("1", 0, 0)
+			},
+			moves: 2,
+			expected: func(t *testing.T, game *model.Game) {
+				assert.Equal(t, model.NoMark, game.Grid[0][3].Mark)
+				assert.Equal(t, 0, len(game.Moves))
+				assert.Equal(t, 2, game.Undos)
+			},
+		},
+		{
+			name:     "OK/UNDO_LOSS_ON_PRACTICE",
+			practice: true,
+			play: func(g gameUsecase) {
+				g.Reveal("1", 0, 0)
+				g.Reveal("1", 0, 2)
+			},
+			moves: 1,
+			expected: func(t *testing.T, game *model.Game) {
+				assert.Equal(t, model.Running, game.Status)
+				assert.Equal(t, model.FinishReason(""), game.FinishReason)
+				assert.True(t, game.FinishTime.IsZero())
+				assert.True(t, game.Grid[0][1].Revealed)
+				assert.False(t, game.Grid[0][2].Revealed)
+				assert.Equal(t, 2, game.CellsRevealed)
+			},
+		},
+		{
+			name: "FAIL/UNDO_LOSS",
+			play: func(g gameUsecase) {
+				g.Reveal("1", 0, 2)
+			},
+			moves:         1,
+			expectedError: OnlyPracticeGamesCanUndoALoss,
+		},
+		{
+			name: "FAIL/UNDO_WIN",
+			play: func(g gameUsecase) {
+				g.Reveal("1", 0, 0)
+				g.Reveal("1", 0, 3)
+			},
+			moves:         1,
+			expectedError: CantUpdateCellsOnAFinishedGame,
+		},
+		{
+			name: "FAIL/NOT_ENOUGH_MOVES",
+			play: func(g gameUsecase) {
+				g.Reveal("1", 0, 0)
+			},
+			moves:         2,
+			expectedError: NotEnoughMovesToUndo,
+		},
+		{
+			name:          "FAIL/NO_MOVES_TO_UNDO",
+			play:          func(g gameUsecase) {},
+			moves:         0,
+			expectedError: MovesToUndoMustBePositive,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			game := &model.Game{
+				Rows:     1,
+				Cols:     4,
+				Mines:    1,
+				Practice: c.practice,
+				Grid: [][]model.Cell{
+					{{MinesAround: 0}, {MinesAround: 1}, {Mine: true}, {MinesAround: 1}},
+				},
+				ResumeTime: time.Now(),
+				Status:     model.Running,
+			}
+			repo := &mockGameRepository{
+				mockAppend: func(game *model.Game, events ...model.Event) *apierr.ApiError {
+					return nil
+				},
+				mockFindByID: func(ID string) (*model.Game, *apierr.ApiError) {
+					return game, nil
+				},
+			}
+			gameUsecase := gameUsecase{
+				service: service.NewGameService(repo),
+				repo:    repo,
+			}
+			c.play(gameUsecase)
+
+			undoneGame, err := gameUsecase.Undo("1", c.moves)
+			if c.expectedError != "" {
+				assert.Equal(t, c.expectedError, err.Error())
+				return
+			}
+			assert.Nil(t, err)
+			c.expected(t, undoneGame)
+		})
+	}
+}
+
+func TestGameUsecaseUndoLossView(t *testing.T) {
+	cases := []struct {
+		name     string
+		practice bool
+		masked   bool
+	}{
+		{
+			name:     "OK/PRACTICE_LOSS_MASKED",
+			practice: true,
+			masked:   true,
+		},
+		{
+			name:   "OK/LOSS_SHOWN",
+			masked: false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			game := &model.Game{
+				Rows:     1,
+				Cols:     4,
+				Mines:    1,
+				Seed:     42,
+				Practice: c.practice,
+				Grid: [][]model.Cell{
+					{{MinesAround: 0}, {MinesAround: 1}, {Mine: true}, {MinesAround: 1}},
+				},
+				ResumeTime: time.Now(),
+				Status:     model.Running,
+			}
+			repo := &mockGameRepository{
+				mockAppend: func(game *model.Game, events ...model.Event) *apierr.ApiError {
+					return nil
+				},
+				mockFindByID: func(ID string) (*model.Game, *apierr.ApiError) {
+					return game, nil
+				},
+			}
+			gameUsecase := gameUsecase{
+				service: service.NewGameService(repo),
+				repo:    repo,
+			}
+
+			lostGame, err := gameUsecase.Reveal("1", 0, 2)
+			assert.Nil(t, err)
+			assert.Equal(t, model.Loose, lostGame.Status)
+			lost := view.NewGameView(lostGame)
+			assert.Equal(t, c.masked, lost.Seed == nil)
+			assert.Equal(t, c.masked, lost.Grid[0][3].Mine == nil)
+			assert.True(t, *lost.Grid[0][2].Mine)
+
+			if !c.practice {
+				return
+			}
+			undoneGame, err := gameUsecase.Undo("1", 1)
+			assert.Nil(t, err)
+			assert.Equal(t, model.Running, undoneGame.Status)
+			undone := view.NewGameView(undoneGame)
+			assert.Nil(t, undone.Seed)
+			assert.Nil(t, undone.Grid[0][2].Mine)
+			assert.Nil(t, undone.Grid[0][3].Mine)
+		})
+	}
+}
+
+func TestGameUsecaseMoves(t *testing.T) {
+	cases := []struct {
+		name          string
+		offset        int
+		limit         int
+		expectedCols  []int
+		expectedError string
+	}{
+		{
+			name:         "OK/FIRST_PAGE",
+			offset:       0,
+			limit:        2,
+			expectedCols: []int{0, 1},
+		},
+		{
+			name:         "OK/LAST_PAGE",
+			offset:       2,
+			limit:        2,
+			expectedCols: []int{2},
+		},
+		{
+			name:         "OK/PAST_THE_END",
+			offset:       5,
+			limit:        2,
+			expectedCols: []int{},
+		},
+		{
+			name:          "FAIL/NEGATIVE_OFFSET",
+			offset:        -1,
+			limit:         2,
+			expectedError: MovesOffsetMustNotBeNegative,
+		},
+		{
+			name:          "FAIL/ZERO_LIMIT",
+			offset:        0,
+			limit:         0,
+			expectedError: MovesLimitOutOfRange,
+		},
+		{
+			name:          "FAIL/LIMIT_OVER_MAX",
+			offset:        0,
+			limit:         MaxMovesPage + 1,
+			expectedError: MovesLimitOutOfRange,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			game := &model.Game{
+				Moves: []model.Move{
+					{Kind: model.FlagMove, Col: 0},
+					{Kind: model.FlagMove, Col: 1},
+					{Kind: model.RevealMove, Col: 2},
+				},
+				LastMoveTime: time.Now(),
+				Status:       model.Running,
+			}
+			repo := &mockGameRepository{
+				mockFindByID: func(ID string) (*model.Game, *apierr.ApiError) {
+					return game, nil
+				},
+			}
+			gameUsecase := gameUsecase{
+				service: service.NewGameService(repo),
+				repo:    repo,
+			}
+
+			moves, total, err := gameUsecase.Moves("1", c.offset, c.limit)
+			if c.expectedError != "" {
+				assert.Equal(t, c.expectedError, err.Error())
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, 3, total)
+			cols := []int{}
+			for _, move := range moves {
+				cols = append(cols, move.Col)
+			}
+			assert.Equal(t, c.expectedCols, cols)
+		})
+	}
+}
+
+func TestGameUsecaseReplay(t *testing.T) {
+	now := time.Now()
+	events := []model.Event{
+		{
+			Kind:     model.CreatedEvent,
+			Time:     now,
+			Settings: &model.GameSettings{Rows: 1, Cols: 4, Mines: 1},
+			Mines:    []model.Coordinate{{Row: 0, Col: 2}},
+		},
+		model.NewCellEvent(model.FlaggedEvent, 0, 2, now),
+		model.NewCellEvent(model.RevealedEvent, 0, 0, now),
+	}
+	cases := []struct {
+		name          string
+		step          int
+		expectedError string
+		expRevealed   int
+	}{
+		{
+			name:        "OK/FIRST_STEP",
+			step:        0,
+			expRevealed: 0,
+		},
+		{
+			name:        "OK/LAST_STEP",
+			step:        2,
+			expRevealed: 2,
+		},
+		{
+			name:          "FAIL/STEP_AFTER_LAST",
+			step:          3,
+			expectedError: ReplayStepOutOfRange,
+		},
+		{
+			name:          "FAIL/NEGATIVE_STEP",
+			step:          -1,
+			expectedError: ReplayStepOutOfRange,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			repo := &mockGameRepository{
+				mockFindByID: func(ID string) (*model.Game, *apierr.ApiError) {
+					return model.Project(events), nil
+				},
+				mockFindEvents: func(ID string) ([]model.Event, *apierr.ApiError) {
+					return events, nil
+				},
+			}
+			gameUsecase := gameUsecase{
+				service: service.NewGameService(repo),
+				repo:    repo,
+			}
+
+			_, replay, err := gameUsecase.Replay("1", c.step)
+			if c.expectedError != "" {
+				assert.Equal(t, c.expectedError, err.Error())
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, c.step, replay.Step)
+			assert.Equal(t, c.expRevealed, replay.Game.CellsRevealed)
+		})
+	}
+}
+
+func TestGameUsecaseHint(t *testing.T) {
+	cases := []struct {
+		name          string
+		game          *model.Game
+		expectedError string
+		expHint       solver.Hint
+	}{
+		{
+			name: "OK/DEDUCED",
+			game: &model.Game{
+				Rows:        1,
+				Cols:        3,
+				Mines:       1,
+				MinesPlaced: true,
+				Grid: [][]model.Cell{
+					{{MinesAround: 0, Revealed: true}, {MinesAround: 1, Revealed: true}, {Mine: true}},
+				},
+				Status: model.Running,
+			},
+			expHint: solver.Hint{
+				Move:        solver.Move{Row: 0, Col: 2, Mine: true, Explanation: "the 1 at (0,1) only has 1 hidden cell left for its 1 missing mine"},
+				Certain:     true,
+				Probability: 1,
+			},
+		},
+		{
+			name: "OK/FIRST_REVEAL",
+			game: &model.Game{
+				Rows:           3,
+				Cols:           5,
+				Mines:          1,
+				FirstClickSafe: true,
+				Grid:           model.NewGrid(3, 5),
+				Status:         model.Running,
+			},
+			expHint: solver.Hint{
+				Move:    solver.Move{Row: 1, Col: 2, Explanation: "the first reveal is always safe"},
+				Certain: true,
+			},
+		},
+		{
+			name: "FAIL/FINISHED_GAME",
+			game: &model.Game{
+				Rows:   1,
+				Cols:   1,
+				Grid:   model.NewGrid(1, 1),
+				Status: model.Win,
+			},
+			expectedError: CantUpdateCellsOnAFinishedGame,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var appended []model.Event
+			repo := &mockGameRepository{
+				mockFindByID: func(ID string) (*model.Game, *apierr.ApiError) {
+					return c.game, nil
+				},
+				mockAppend: func(game *model.Game, events ...model.Event) *apierr.ApiError {
+					appended = append(appended, events...)
+					return nil
+				},
+			}
+			gameUsecase := gameUsecase{
+				service: service.NewGameService(repo),
+				repo:    repo,
+			}
+
+			game, hint, err := gameUsecase.Hint("1")
+			if c.expectedError != "" {
+				assert.Equal(t, c.expectedError, err.Error())
+				assert.Empty(t, appended)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, c.expHint, hint)
+			assert.Equal(t, 1, game.HintsUsed)
+			assert.Equal(t, model.HintedEvent, appended[0].Kind)
+		})
+	}
+}
+
+func TestGameUsecaseAutosolve(t *testing.T) {
+	newGame := func(rows, cols int, mines []model.Coordinate, revealed ...model.Coordinate) *model.Game {
+		game := &model.Game{
+			Rows:        rows,
+			Cols:        cols,
+			Mines:       len(mines),
+			MinesPlaced: true,
+			Grid:        model.NewGrid(rows, cols),
+			Status:      model.Running,
+		}
+		for _, mine := range mines {
+			game.Grid[mine.Row][mine.Col].Mine = true
+		}
+		game.CountMinesAround()
+		for _, cell := range revealed {
+			game.Apply(model.NewCellEvent(model.RevealedEvent, cell.Row, cell.Col, time.Now()))
+		}
+		return game
+	}
+
+	cases := []struct {
+		name          string
+		game          *model.Game
+		expectedError string
+		expMoves      []model.Move
+		expStatus     model.GameStatus
+	}{
+		{
+			name: "OK/SOLVED",
+			game: newGame(1, 4, []model.Coordinate{{Row: 0, Col: 2}}, model.Coordinate{Row: 0, Col: 0}),
+			expMoves: []model.Move{
+				{Kind: model.FlagMove, Row: 0, Col: 2, Mark: model.Flag},
+				{Kind: model.RevealMove, Row: 0, Col: 3},
+			},
+			expStatus: model.Win,
+		},
+		{
+			name:      "OK/NOTHING_TO_DEDUCE",
+			game:      newGame(1, 3, []model.Coordinate{{Row: 0, Col: 1}}),
+			expMoves:  []model.Move{},
+			expStatus: model.Running,
+		},
+		{
+			name: "FAIL/PAUSED_GAME",
+			game: &model.Game{
+				Rows:   1,
+				Cols:   1,
+				Grid:   model.NewGrid(1, 1),
+				Status: model.Paused,
+			},
+			expectedError: CantUpdateCellsOnAPausedGame,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var appended []model.Event
+			repo := &mockGameRepository{
+				mockFindByID: func(ID string) (*model.Game, *apierr.ApiError) {
+					return c.game, nil
+				},
+				mockAppend: func(game *model.Game, events ...model.Event) *apierr.ApiError {
+					appended = append(appended, events...)
+					return nil
+				},
+			}
+			gameUsecase := gameUsecase{
+				service: service.NewGameService(repo),
+				repo:    repo,
+			}
+
+			game, moves, err := gameUsecase.Autosolve("1")
+			if c.expectedError != "" {
+				assert.Equal(t, c.expectedError, err.Error())
+				assert.Empty(t, appended)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, c.expStatus, game.Status)
+			played := []model.Move{}
+			for _, move := range moves {
+				move.Time = time.Time{}
+				played = append(played, move)
+			}
+			assert.Equal(t, c.expMoves, played)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package usecase
+
+import (
+	"github.com/egorkos/minesweeper/app/domain/model"
+	"github.com/egorkos/minesweeper/app/domain/repository"
+	"github.com/egorkos/minesweeper/app/interface/apierr"
+)
+
+type PresetUsecase interface {
+	FindAll() ([]*model.Preset, *apierr.ApiError)
+	Save(preset model.Preset) (model.Preset, *apierr.ApiError)
+}
+
+type presetUsecase struct {
+	repo repository.PresetRepository
+}
+
+func NewPresetUsecase(repo repository.PresetRepository) *presetUsecase {
+	return &presetUsecase{
+		repo: repo,
+	}
+}
+
+// FindAll returns the builtin presets followed by the saved templates.
+func (p *presetUsecase) FindAll() ([]*model.Preset, *apierr.ApiError) {
+	templates, err := p.repo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var presets []*model.Preset
+	for _, preset := range model.BuiltinPresets() {
+		preset := preset
+		presets = append(presets, &preset)
+	}
+
+	return append(presets, templates...), nil
+}
+
+func (p *presetUsecase) Save(preset model.Preset) (model.Preset, *apierr.ApiError) {
+	err := p.repo.Upsert(&preset)
+	if err != nil {
+		return model.Preset{}, err
+	}
+
+	return preset, nil
+}